@@ -5,12 +5,20 @@ import (
 	"log"
 	"net"
 	"os"
+	"strings"
 
 	"MMA2.0/internal/authority"
 	"MMA2.0/internal/config"
+	"MMA2.0/internal/egress"
 	"MMA2.0/internal/ingress"
+	"MMA2.0/internal/memorycore"
+	"MMA2.0/internal/poller"
+	"MMA2.0/internal/transport/execingest"
 	"MMA2.0/internal/transport/modbus"
+	"MMA2.0/internal/transport/mqtt"
+	"MMA2.0/internal/transport/raw"
 	"MMA2.0/internal/transport/rawingest"
+	"MMA2.0/internal/transport/rest"
 )
 
 func main() {
@@ -59,29 +67,81 @@ func main() {
 		auth.SetMemoryPolicy(mid, p)
 	}
 
+	if err := config.RegisterStateSealing(cfg, store, auth.Sealing()); err != nil {
+		log.Fatalf("state sealing build failed: %v", err)
+	}
+
 	log.Println("authority policies loaded")
 
+	// --------------------
+	// Start egress (write-back) subscriptions
+	// --------------------
+
+	dispatcher := startEgress(cfg.Egress)
+
 	// --------------------
 	// Start ingress listeners
 	// --------------------
 
+	// Diagnostic counters are keyed by MemoryID: one table for all listeners.
+	diagnostics := modbus.NewDiagnostics()
+
 	for _, gate := range cfg.Ingress {
 
-		onModbus := func(conn net.Conn) {
-			modbus.HandleConn(conn, store, auth)
+		if gate.IsUDPIngest() {
+			startUDPIngest(gate, store, auth)
+			continue
+		}
+
+		maxPacketBytes := gate.MaxPacketBytes
+		targets := config.IngestTargets(gate)
+
+		mb := &modbus.Server{
+			Store:       store,
+			Auth:        auth,
+			Gateway:     buildGateway(gate),
+			Diagnostics: diagnostics,
+		}
+		if dispatcher != nil {
+			mb.Observer = dispatcher
 		}
 
-		onRawIngest := func(conn net.Conn) {
-			rawingest.HandleConn(conn, store)
+		handlers := ingress.Handlers{
+			Modbus: mb.HandleConn,
+			RawIngest: func(conn net.Conn) {
+				rawingest.HandleConn(conn, store, auth)
+			},
+			RawFrame: func(conn net.Conn) {
+				raw.HandleConn(conn, store, auth, maxPacketBytes, targets)
+			},
 		}
 
-		l := ingress.NewListener(gate)
+		l, err := ingress.NewListener(gate)
+		if err != nil {
+			log.Fatalf("ingress %s build failed: %v", gate.ID, err)
+		}
 
-		go func(g ingress.Listener) {
-			if err := g.ListenAndServe(onModbus, onRawIngest); err != nil {
-				log.Fatalf("ingress %s failed: %v", gate.ID, err)
+		go func(id string, g *ingress.Listener) {
+			if err := g.ListenAndServe(handlers); err != nil {
+				log.Fatalf("ingress %s failed: %v", id, err)
 			}
-		}(*l)
+		}(gate.ID, l)
+	}
+
+	for _, rl := range cfg.REST {
+		startREST(rl, store, auth)
+	}
+
+	for _, b := range cfg.MQTT {
+		startMQTT(b, store, auth)
+	}
+
+	for _, a := range cfg.Exec {
+		startExec(a, store, auth)
+	}
+
+	for _, p := range cfg.Pollers {
+		startPoller(p, store, auth)
 	}
 
 	log.Println("mma2 ingress started")
@@ -92,3 +152,238 @@ func main() {
 
 	select {}
 }
+
+// startUDPIngest starts one kind: udp_ingest listener.
+func startUDPIngest(gate config.IngressGate, store *memorycore.Store, auth *authority.Authority) {
+	port, err := config.ListenPort(gate)
+	if err != nil {
+		log.Fatalf("ingress %s build failed: %v", gate.ID, err)
+	}
+
+	l, err := ingress.NewUDPListener(gate)
+	if err != nil {
+		log.Fatalf("ingress %s build failed: %v", gate.ID, err)
+	}
+
+	h := rawingest.NewUDPHandler(store, auth, port, gate.Reply)
+
+	// done stops the stats reporter when the listener shuts down.
+	done := make(chan struct{})
+	go h.LogStats(gate.ID, done)
+
+	go func() {
+		err := l.ListenAndServe(h.HandleDatagram)
+		close(done)
+		if err != nil {
+			log.Fatalf("ingress %s failed: %v", gate.ID, err)
+		}
+	}()
+}
+
+// startREST starts one write-only REST ingest listener.
+func startREST(rl config.RESTListener, store *memorycore.Store, auth *authority.Authority) {
+	fw, err := ingress.NewFirewall(rl.Allow, rl.Deny)
+	if err != nil {
+		log.Fatalf("rest %s build failed: %v", rl.ID, err)
+	}
+
+	srv := rest.NewServer(rest.Options{
+		ID:           rl.ID,
+		Listen:       rl.Listen,
+		Path:         rl.Path,
+		MaxBodyBytes: rl.MaxBodyBytes,
+		Permit:       fw.Permit,
+	}, store, auth)
+
+	go func() {
+		if err := srv.ListenAndServe(); err != nil {
+			log.Fatalf("rest %s failed: %v", rl.ID, err)
+		}
+	}()
+}
+
+// startMQTT starts one MQTT ingest client (reconnects forever).
+func startMQTT(b config.MQTTBroker, store *memorycore.Store, auth *authority.Authority) {
+	password, err := config.MQTTPassword(b)
+	if err != nil {
+		log.Fatalf("mqtt %s build failed: %v", b.ID, err)
+	}
+
+	// Durations are validated by config.Validate.
+	keepAlive, _ := config.OptionalDuration(b.KeepAlive)
+	minBackoff, _ := config.OptionalDuration(b.MinBackoff)
+	maxBackoff, _ := config.OptionalDuration(b.MaxBackoff)
+
+	subs := make([]mqtt.Subscription, 0, len(b.Subscriptions))
+	for _, s := range b.Subscriptions {
+		format := mqtt.FormatJSON
+		if strings.EqualFold(strings.TrimSpace(s.Format), "raw") {
+			format = mqtt.FormatRaw
+		}
+		subs = append(subs, mqtt.Subscription{
+			Topic:  s.Topic,
+			QoS:    s.QoS,
+			Format: format,
+			Port:   s.Port,
+		})
+	}
+
+	c := mqtt.NewClient(mqtt.Options{
+		ID:            b.ID,
+		Broker:        b.Broker,
+		ClientID:      b.ClientID,
+		Username:      b.Username,
+		Password:      password,
+		KeepAlive:     keepAlive,
+		CleanSession:  b.CleanSession,
+		Subscriptions: subs,
+		MinBackoff:    minBackoff,
+		MaxBackoff:    maxBackoff,
+	}, store, auth)
+
+	go c.Run()
+}
+
+// startExec starts one exec adapter (restarts its child forever).
+func startExec(a config.ExecAdapter, store *memorycore.Store, auth *authority.Authority) {
+	// Durations are validated by config.Validate.
+	minBackoff, _ := config.OptionalDuration(a.MinBackoff)
+	maxBackoff, _ := config.OptionalDuration(a.MaxBackoff)
+
+	ad := execingest.NewAdapter(execingest.Options{
+		ID:         a.ID,
+		Command:    a.Command,
+		Dir:        a.Dir,
+		Env:        a.Env,
+		MinBackoff: minBackoff,
+		MaxBackoff: maxBackoff,
+	}, store, auth)
+
+	go ad.Run()
+}
+
+// startPoller starts one Modbus TCP poller.
+func startPoller(p config.PollerDevice, store *memorycore.Store, auth *authority.Authority) {
+	// Durations and areas are validated by config.Validate.
+	interval, _ := config.OptionalDuration(p.Interval)
+	timeout, _ := config.OptionalDuration(p.Timeout)
+
+	reads := make([]poller.Read, 0, len(p.Reads))
+	for _, r := range p.Reads {
+		local := r.Address
+		if r.LocalAddress != nil {
+			local = *r.LocalAddress
+		}
+		reads = append(reads, poller.Read{
+			Function:     r.Function,
+			Address:      r.Address,
+			Count:        r.Count,
+			LocalAddress: local,
+		})
+	}
+
+	var status *poller.Status
+	if p.Status != nil {
+		status = &poller.Status{Area: p.Status.ResolvedArea, Address: p.Status.Address}
+	}
+
+	pl, err := poller.New(poller.Options{
+		ID:       p.ID,
+		Address:  p.Address,
+		UnitID:   uint8(p.UnitID),
+		Interval: interval,
+		Timeout:  timeout,
+		Retries:  p.Retries,
+		Target:   memorycore.MemoryID{Port: p.Target.Port, UnitID: p.Target.UnitID},
+		Reads:    reads,
+		Status:   status,
+	}, store, auth)
+	if err != nil {
+		log.Fatalf("poller %s build failed: %v", p.ID, err)
+	}
+
+	go pl.Run()
+}
+
+// startEgress starts every egress subscription and returns the dispatcher
+// feeding them (nil if none are configured).
+func startEgress(subs []config.EgressSubscription) *egress.Dispatcher {
+	if len(subs) == 0 {
+		return nil
+	}
+
+	d := egress.NewDispatcher()
+
+	for _, e := range subs {
+		// Areas, ranges and durations are validated by config.Validate.
+		timeout, _ := config.OptionalDuration(e.Sink.Timeout)
+
+		unit := e.Source.UnitID
+		if e.Sink.UnitID != nil {
+			unit = *e.Sink.UnitID
+		}
+		remote := e.Start
+		if e.Sink.RemoteAddress != nil {
+			remote = *e.Sink.RemoteAddress
+		}
+		retries := egress.DefaultRetries
+		if e.Retries != nil {
+			retries = *e.Retries
+		}
+
+		var sink egress.Sink
+		switch e.Sink.Type {
+		case "tcp":
+			sink = &egress.TCPSink{Address: e.Sink.Address, UnitID: unit, Timeout: timeout}
+		case "webhook":
+			sink = &egress.WebhookSink{URL: e.Sink.URL, Timeout: timeout}
+		case "modbus":
+			sink = &egress.ModbusSink{Address: e.Sink.Address, UnitID: uint8(unit), Timeout: timeout}
+		}
+
+		s, err := egress.NewSubscription(egress.Options{
+			ID:            e.ID,
+			Memory:        memorycore.MemoryID{Port: e.Source.Port, UnitID: e.Source.UnitID},
+			Area:          e.ResolvedArea,
+			Start:         e.Start,
+			Count:         e.Count,
+			RemoteAddress: remote,
+			QueueSize:     e.QueueSize,
+			Retries:       retries,
+		}, sink)
+		if err != nil {
+			log.Fatalf("egress %s build failed: %v", e.ID, err)
+		}
+
+		d.Add(s)
+		go s.Run()
+	}
+
+	return d
+}
+
+// buildGateway builds the Modbus routing table of one listener (nil if none).
+func buildGateway(gate config.IngressGate) *modbus.Gateway {
+	if len(gate.Gateway) == 0 {
+		return nil
+	}
+
+	routes := make([]modbus.Route, 0, len(gate.Gateway))
+	for _, r := range gate.Gateway {
+		remote := r.UnitID
+		if r.RemoteUnitID != nil {
+			remote = *r.RemoteUnitID
+		}
+		// Timeouts are validated by config.Validate.
+		timeout, _ := config.OptionalDuration(r.Timeout)
+
+		routes = append(routes, modbus.Route{
+			UnitID:       uint8(r.UnitID),
+			Address:      r.Address,
+			RemoteUnitID: uint8(remote),
+			Timeout:      timeout,
+		})
+	}
+
+	return modbus.NewGateway(routes)
+}
@@ -4,6 +4,7 @@ package authority
 import (
 	"net/netip"
 	"sync"
+	"time"
 
 	"MMA2.0/internal/memorycore"
 )
@@ -15,18 +16,50 @@ const (
 	ExceptionDeviceBusy      = 0x06
 )
 
+// RequestKind selects which policy a request is evaluated against.
+type RequestKind uint8
+
+const (
+	// KindModbus is a Modbus client request (zero value).
+	KindModbus RequestKind = iota
+
+	// KindIngest is an ingest write (raw ingest and other write-only transports).
+	KindIngest
+)
+
 // Request is the minimum information needed to decide access.
 // No Modbus parsing, IO, or memory operations happen here.
 type Request struct {
-	MemoryID     memorycore.MemoryID
-	SourceIP     netip.Addr
+	Kind RequestKind
+
+	MemoryID memorycore.MemoryID
+	SourceIP netip.Addr
+
+	// KindModbus
 	FunctionCode uint8
+
+	// KindIngest: explicit write target
+	Area    memorycore.Area
+	Address uint16
+	Count   uint16
+
+	// KindIngest: the transport verified the frame via AuthenticateIngest.
+	Authenticated bool
 }
 
 // MemoryPolicy is per-memory authorization configuration.
 type MemoryPolicy struct {
 	// Rules evaluated top-down; first match wins; default deny.
 	Rules []*Rule
+
+	// Ingest is the optional ingest policy.
+	// nil = ingest allowed from any source (legacy behavior).
+	Ingest *IngestPolicy
+
+	// IngestKeys are shared HMAC-SHA256 keys for authenticated ingest.
+	// Non-empty = every ingest write must be authenticated.
+	// More than one key = rotation (any key verifies).
+	IngestKeys [][]byte
 }
 
 // Authority evaluates state sealing + memory-scoped access rules.
@@ -35,12 +68,24 @@ type Authority struct {
 
 	mu       sync.RWMutex
 	policies map[memorycore.MemoryID]*MemoryPolicy
+
+	// Last accepted ingest nonce per memory (replay protection).
+	// startNonce is the process start time: the floor for every memory.
+	nonceMu    sync.Mutex
+	nonces     map[memorycore.MemoryID]uint64
+	startNonce uint64
+
+	now func() time.Time // clock for the nonce window
 }
 
 func New() *Authority {
 	return &Authority{
 		sealing:  NewSealing(),
 		policies: make(map[memorycore.MemoryID]*MemoryPolicy),
+		nonces:   make(map[memorycore.MemoryID]uint64),
+
+		startNonce: uint64(time.Now().UnixNano()),
+		now:        time.Now,
 	}
 }
 
@@ -58,7 +103,14 @@ func (a *Authority) SetMemoryPolicy(mid memorycore.MemoryID, p *MemoryPolicy) {
 // 1) state sealing check -> Device Busy (0x06)
 // 2) access rules top-down -> first match wins
 // 3) default deny if no match or no policy
+//
+// Ingest requests skip state sealing (ingest is always allowed in PRE-RUN)
+// and are evaluated against the ingest policy instead.
 func (a *Authority) Evaluate(req Request) Decision {
+	if req.Kind == KindIngest {
+		return a.evaluateIngest(req)
+	}
+
 	// Step 1: state sealing
 	if a.sealing.IsSealed(req.MemoryID) {
 		return Deny(ExceptionDeviceBusy, "state sealing enabled")
@@ -5,4 +5,8 @@ import "errors"
 
 var (
 	ErrInvalidRule = errors.New("authority: invalid rule")
+
+	ErrIngestAuthNotConfigured = errors.New("authority: ingest authentication not configured")
+	ErrIngestAuthFailed        = errors.New("authority: ingest authentication failed")
+	ErrIngestReplay            = errors.New("authority: ingest nonce replayed")
 )
@@ -0,0 +1,119 @@
+// internal/authority/ingest.go
+package authority
+
+import (
+	"fmt"
+	"net/netip"
+
+	"MMA2.0/internal/memorycore"
+)
+
+// IngestPolicy is per-memory ingest authorization.
+// Rules evaluated top-down; first match wins; default deny.
+type IngestPolicy struct {
+	Rules []*IngestRule
+}
+
+// IngestRange is a writable window inside one memory area.
+type IngestRange struct {
+	Area  memorycore.Area
+	Start uint16
+	Count uint16
+}
+
+// Contains reports whether [address, address+count) lies inside the range.
+func (r IngestRange) Contains(area memorycore.Area, address uint16, count uint16) bool {
+	if area != r.Area {
+		return false
+	}
+	l := memorycore.AreaLayout{Start: r.Start, Size: r.Count}
+	return l.Contains(address, count)
+}
+
+// IngestRule matches a source IP and allows writes to explicit ranges.
+type IngestRule struct {
+	ID string
+
+	IP *IPMatcher
+
+	Allow []IngestRange
+}
+
+func NewIngestRule(id string, ipList []string, allow []IngestRange) (*IngestRule, error) {
+	if id == "" {
+		return nil, fmt.Errorf("%w: rule id required", ErrInvalidRule)
+	}
+
+	m, err := NewIPMatcher(ipList)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, r := range allow {
+		if r.Area == memorycore.AreaInvalid {
+			return nil, fmt.Errorf("%w: allow[%d]: invalid area", ErrInvalidRule, i)
+		}
+		if err := (memorycore.AreaLayout{Start: r.Start, Size: r.Count}).Validate(); err != nil {
+			return nil, fmt.Errorf("%w: allow[%d]: %v", ErrInvalidRule, i, err)
+		}
+	}
+
+	return &IngestRule{
+		ID:    id,
+		IP:    m,
+		Allow: allow,
+	}, nil
+}
+
+func (r *IngestRule) Matches(src netip.Addr) bool {
+	if r == nil || r.IP == nil {
+		return false
+	}
+	return r.IP.Match(src)
+}
+
+func (r *IngestRule) Allows(area memorycore.Area, address uint16, count uint16) bool {
+	if r == nil {
+		return false
+	}
+	for _, rg := range r.Allow {
+		if rg.Contains(area, address, count) {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateIngest applies the ingest policy of the target memory.
+func (a *Authority) evaluateIngest(req Request) Decision {
+	a.mu.RLock()
+	p := a.policies[req.MemoryID]
+	a.mu.RUnlock()
+
+	if p != nil && len(p.IngestKeys) > 0 && !req.Authenticated {
+		return Deny(ExceptionIllegalFunction, "ingest authentication required")
+	}
+
+	if p == nil || p.Ingest == nil {
+		return Allow("no ingest policy (ingest open)")
+	}
+
+	for _, r := range p.Ingest.Rules {
+		if r == nil {
+			continue
+		}
+
+		if !r.Matches(req.SourceIP) {
+			continue
+		}
+
+		// First match wins.
+		if r.Allows(req.Area, req.Address, req.Count) {
+			return Allow("matched ingest rule: " + r.ID)
+		}
+
+		return Deny(ExceptionIllegalFunction, "ingest rule matched but range not allowed: "+r.ID)
+	}
+
+	return Deny(ExceptionIllegalFunction, "no ingest rule matched (default deny)")
+}
@@ -0,0 +1,92 @@
+// internal/authority/ingest_auth_test.go
+package authority
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"MMA2.0/internal/memorycore"
+)
+
+func newAuthWithKey(t *testing.T, key []byte, mids ...memorycore.MemoryID) (*Authority, *time.Time) {
+	t.Helper()
+
+	now := time.Unix(1_700_000_000, 0)
+	a := New()
+	a.startNonce = uint64(now.Add(-time.Minute).UnixNano())
+	a.now = func() time.Time { return now }
+
+	for _, mid := range mids {
+		a.SetMemoryPolicy(mid, &MemoryPolicy{IngestKeys: [][]byte{key}})
+	}
+	return a, &now
+}
+
+func TestAuthenticateIngestBindsMemory(t *testing.T) {
+	key := []byte("shared-key")
+	m1 := memorycore.MemoryID{Port: 502, UnitID: 1}
+	m2 := memorycore.MemoryID{Port: 1502, UnitID: 1}
+	a, now := newAuthWithKey(t, key, m1, m2)
+
+	msg := []byte("frame")
+	nonce := uint64(now.UnixNano())
+	tag := IngestTag(key, m1, msg, nonce)
+
+	// The same frame replayed to another memory sharing the key.
+	if err := a.AuthenticateIngest(m2, msg, nonce, tag); !errors.Is(err, ErrIngestAuthFailed) {
+		t.Fatalf("other memory: err = %v, want %v", err, ErrIngestAuthFailed)
+	}
+	if err := a.AuthenticateIngest(m1, msg, nonce, tag); err != nil {
+		t.Fatalf("target memory: %v", err)
+	}
+}
+
+func TestAuthenticateIngestNonce(t *testing.T) {
+	key := []byte("k")
+	mid := memorycore.MemoryID{Port: 502, UnitID: 1}
+	a, now := newAuthWithKey(t, key, mid)
+
+	auth := func(nonce uint64) error {
+		return a.AuthenticateIngest(mid, nil, nonce, IngestTag(key, mid, nil, nonce))
+	}
+	at := func(d time.Duration) uint64 { return uint64(now.Add(d).UnixNano()) }
+
+	for _, tc := range []struct {
+		name  string
+		nonce uint64
+		ok    bool
+	}{
+		{"before process start", at(-2 * time.Minute), false},
+		{"outside window (past)", at(-IngestNonceWindow - time.Second), false},
+		{"outside window (future)", at(IngestNonceWindow + time.Second), false},
+		{"not a timestamp", 1 << 63, false},
+		{"counter", 1, false},
+		{"fresh", at(0), true},
+		{"repeated", at(0), false},
+		{"older than last", at(-time.Second), false},
+		{"newer", at(time.Millisecond), true},
+	} {
+		err := auth(tc.nonce)
+		if tc.ok && err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+		}
+		if !tc.ok && !errors.Is(err, ErrIngestReplay) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, ErrIngestReplay)
+		}
+	}
+}
+
+func TestAuthenticateIngestForgedDoesNotBurnNonce(t *testing.T) {
+	key := []byte("k")
+	mid := memorycore.MemoryID{Port: 502, UnitID: 1}
+	a, now := newAuthWithKey(t, key, mid)
+
+	nonce := uint64(now.UnixNano())
+	if err := a.AuthenticateIngest(mid, nil, nonce, make([]byte, IngestTagSize)); !errors.Is(err, ErrIngestAuthFailed) {
+		t.Fatalf("forged: err = %v, want %v", err, ErrIngestAuthFailed)
+	}
+	if err := a.AuthenticateIngest(mid, nil, nonce, IngestTag(key, mid, nil, nonce)); err != nil {
+		t.Fatalf("genuine after forged: %v", err)
+	}
+}
@@ -45,10 +45,20 @@ func (r *Rule) Matches(src netip.Addr) bool {
 	return r.IP.Match(src)
 }
 
+// AllowsFC reports whether the rule allows a function code.
+// FC23 (read/write multiple registers) is both a read and a write:
+// it must be listed and requires FC3 and FC16 as well.
 func (r *Rule) AllowsFC(fc uint8) bool {
 	if r == nil {
 		return false
 	}
-	_, ok := r.AllowFunctionCodes[fc]
-	return ok
+	if _, ok := r.AllowFunctionCodes[fc]; !ok {
+		return false
+	}
+	if fc == 23 {
+		_, read := r.AllowFunctionCodes[3]
+		_, write := r.AllowFunctionCodes[16]
+		return read && write
+	}
+	return true
 }
@@ -0,0 +1,25 @@
+// internal/authority/rules_test.go
+package authority
+
+import "testing"
+
+func TestRuleAllowsFC23(t *testing.T) {
+	for _, tc := range []struct {
+		allow []uint8
+		want  bool
+	}{
+		{[]uint8{3, 16, 23}, true},
+		{[]uint8{23}, false},     // no read, no write
+		{[]uint8{3, 23}, false},  // read only
+		{[]uint8{16, 23}, false}, // write only
+		{[]uint8{3, 16}, false},  // not listed
+	} {
+		r, err := NewRule("r", []string{"127.0.0.1"}, tc.allow)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := r.AllowsFC(23); got != tc.want {
+			t.Errorf("allow_fc %v: AllowsFC(23) = %v, want %v", tc.allow, got, tc.want)
+		}
+	}
+}
@@ -2,47 +2,70 @@
 package authority
 
 import (
+	"log"
 	"sync"
-	"sync/atomic"
 
 	"MMA2.0/internal/memorycore"
 )
 
 // Sealing is policy state (NOT a memory lock).
 // Locked behavior: if sealed, Modbus must return Device Busy (0x06).
+//
+// Lifecycle state is OWNED by memorycore (PRE-RUN / RUN).
+// Sealing is the single authoritative view of it:
+//   - Evaluate reads it (the only place that returns Device Busy)
+//   - Ingest transports drive transitions through it
 type Sealing struct {
-	mu    sync.RWMutex
-	flags map[memorycore.MemoryID]*atomic.Bool
+	mu   sync.RWMutex
+	mems map[memorycore.MemoryID]*memorycore.Memory
 }
 
 func NewSealing() *Sealing {
 	return &Sealing{
-		flags: make(map[memorycore.MemoryID]*atomic.Bool),
+		mems: make(map[memorycore.MemoryID]*memorycore.Memory),
 	}
 }
 
-func (s *Sealing) Ensure(mid memorycore.MemoryID, enabled bool) {
+// Register attaches a sealing-enabled memory.
+// Intended for startup config load.
+func (s *Sealing) Register(mid memorycore.MemoryID, mem *memorycore.Memory) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.mems[mid] = mem
+	s.mu.Unlock()
 
-	b, ok := s.flags[mid]
-	if !ok {
-		b = &atomic.Bool{}
-		s.flags[mid] = b
-	}
-	b.Store(enabled)
+	log.Printf("authority: state sealing enabled for memory (port=%d unit=%d): %s", mid.Port, mid.UnitID, mem.Lifecycle())
 }
 
+// IsSealed reports whether the memory is in PRE-RUN.
+// Memories that were never registered are always RUN.
 func (s *Sealing) IsSealed(mid memorycore.MemoryID) bool {
 	s.mu.RLock()
-	b := s.flags[mid]
+	mem := s.mems[mid]
 	s.mu.RUnlock()
 
-	if b == nil {
+	if mem == nil {
 		return false
 	}
-	return b.Load()
+	return mem.Lifecycle() == memorycore.LifecyclePreRun
 }
 
-func (s *Sealing) Seal(mid memorycore.MemoryID)   { s.Ensure(mid, true) }
-func (s *Sealing) Unseal(mid memorycore.MemoryID) { s.Ensure(mid, false) }
+// ObserveIngestWrite forwards an applied ingest bit write to the memory
+// lifecycle. It returns true if the write unlocked the memory (PRE-RUN -> RUN).
+//
+// Only ingest transports may call this. Modbus never does.
+func (s *Sealing) ObserveIngestWrite(mid memorycore.MemoryID, area memorycore.Area, address uint16, count uint16, src []byte) bool {
+	s.mu.RLock()
+	mem := s.mems[mid]
+	s.mu.RUnlock()
+
+	if mem == nil {
+		return false
+	}
+
+	if !mem.ObserveIngestWrite(area, address, count, src) {
+		return false
+	}
+
+	log.Printf("authority: memory (port=%d unit=%d) unsealed: PRE-RUN -> RUN", mid.Port, mid.UnitID)
+	return true
+}
@@ -26,7 +26,7 @@ func BuildAuthorityPolicies(cfg *Config) (map[memorycore.MemoryID]*authority.Mem
 	// Legacy model: cfg.Memory.Memories (canonical runtime model)
 	// ---------------------------
 	for key, def := range cfg.Memory.Memories {
-		if def.Policy == nil {
+		if def.Policy == nil && def.IngestPolicy == nil && def.IngestAuth == nil {
 			continue
 		}
 
@@ -39,7 +39,7 @@ func BuildAuthorityPolicies(cfg *Config) (map[memorycore.MemoryID]*authority.Mem
 			return nil, fmt.Errorf("duplicate policy for memory (port=%d unit_id=%d) from legacy memory[%s]", mid.Port, mid.UnitID, key)
 		}
 
-		p, err := buildPolicyFromDef(def, fmt.Sprintf("memory[%s]", key))
+		p, err := buildPolicyFromDef(def, nil, fmt.Sprintf("memory[%s]", key))
 		if err != nil {
 			return nil, err
 		}
@@ -61,7 +61,7 @@ func BuildAuthorityPolicies(cfg *Config) (map[memorycore.MemoryID]*authority.Mem
 		}
 
 		for mi, def := range ing.Memory {
-			if def.Policy == nil {
+			if def.Policy == nil && def.IngestPolicy == nil && def.IngestAuth == nil && ing.IngestAuth == nil {
 				continue
 			}
 
@@ -78,7 +78,43 @@ func BuildAuthorityPolicies(cfg *Config) (map[memorycore.MemoryID]*authority.Mem
 			}
 
 			ctx := fmt.Sprintf("listeners[%d] (%s).memory[%d]", li, ing.ID, mi)
-			p, err := buildPolicyFromDef(def, ctx)
+			p, err := buildPolicyFromDef(def, ing.IngestAuth, ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			out[mid] = p
+		}
+	}
+
+	// ---------------------------
+	// Gateway routes: listeners[].gateway[]
+	// ---------------------------
+	for li, ing := range cfg.Ingress {
+		if len(ing.Gateway) == 0 {
+			continue
+		}
+
+		port, err := parseListenPort(ing.Listen)
+		if err != nil {
+			return nil, fmt.Errorf("listeners[%d] (%s) listen=%q: %w", li, ing.ID, ing.Listen, err)
+		}
+
+		for ri, r := range ing.Gateway {
+			if r.Policy == nil {
+				continue
+			}
+
+			mid := memorycore.MemoryID{Port: port, UnitID: r.UnitID}
+			if _, exists := out[mid]; exists {
+				return nil, fmt.Errorf(
+					"duplicate policy for memory (port=%d unit_id=%d): listeners[%d] (%s).gateway[%d] conflicts with an existing definition",
+					mid.Port, mid.UnitID, li, ing.ID, ri,
+				)
+			}
+
+			ctx := fmt.Sprintf("listeners[%d] (%s).gateway[%d]", li, ing.ID, ri)
+			p, err := buildPolicyFromDef(MemoryDefinition{Policy: r.Policy}, nil, ctx)
 			if err != nil {
 				return nil, err
 			}
@@ -90,21 +126,67 @@ func BuildAuthorityPolicies(cfg *Config) (map[memorycore.MemoryID]*authority.Mem
 	return out, nil
 }
 
-func buildPolicyFromDef(def MemoryDefinition, ctx string) (*authority.MemoryPolicy, error) {
-	if def.Policy == nil {
+// buildPolicyFromDef builds the runtime policy of one memory.
+// listenerAuth is the listener-level ingest_auth default (may be nil).
+func buildPolicyFromDef(def MemoryDefinition, listenerAuth *IngestAuthConfig, ctx string) (*authority.MemoryPolicy, error) {
+	ingestAuth := def.IngestAuth
+	if ingestAuth == nil {
+		ingestAuth = listenerAuth
+	}
+
+	if def.Policy == nil && def.IngestPolicy == nil && ingestAuth == nil {
 		return nil, nil
 	}
 
-	p := &authority.MemoryPolicy{
-		Rules: make([]*authority.Rule, 0, len(def.Policy.Rules)),
+	p := &authority.MemoryPolicy{}
+
+	if def.Policy != nil {
+		p.Rules = make([]*authority.Rule, 0, len(def.Policy.Rules))
+
+		for i, rc := range def.Policy.Rules {
+			r, err := authority.NewRule(rc.ID, rc.SourceIP, rc.AllowFC)
+			if err != nil {
+				return nil, fmt.Errorf("%s.policy.rules[%d] (%s): %w", ctx, i, rc.ID, err)
+			}
+			p.Rules = append(p.Rules, r)
+		}
+	}
+
+	if def.IngestPolicy != nil {
+		ip := &authority.IngestPolicy{
+			Rules: make([]*authority.IngestRule, 0, len(def.IngestPolicy.Rules)),
+		}
+
+		for i, rc := range def.IngestPolicy.Rules {
+			ranges := make([]authority.IngestRange, 0, len(rc.Allow))
+			for j, ac := range rc.Allow {
+				area, err := memorycore.ParseArea(ac.Area)
+				if err != nil {
+					return nil, fmt.Errorf("%s.ingest_policy.rules[%d] (%s).allow[%d]: %w", ctx, i, rc.ID, j, err)
+				}
+				ranges = append(ranges, authority.IngestRange{
+					Area:  area,
+					Start: ac.Start,
+					Count: ac.Count,
+				})
+			}
+
+			r, err := authority.NewIngestRule(rc.ID, rc.SourceIP, ranges)
+			if err != nil {
+				return nil, fmt.Errorf("%s.ingest_policy.rules[%d] (%s): %w", ctx, i, rc.ID, err)
+			}
+			ip.Rules = append(ip.Rules, r)
+		}
+
+		p.Ingest = ip
 	}
 
-	for i, rc := range def.Policy.Rules {
-		r, err := authority.NewRule(rc.ID, rc.SourceIP, rc.AllowFC)
+	if ingestAuth != nil {
+		keys, err := loadIngestKeys(ingestAuth)
 		if err != nil {
-			return nil, fmt.Errorf("%s.policy.rules[%d] (%s): %w", ctx, i, rc.ID, err)
+			return nil, fmt.Errorf("%s: %w", ctx, err)
 		}
-		p.Rules = append(p.Rules, r)
+		p.IngestKeys = keys
 	}
 
 	return p, nil
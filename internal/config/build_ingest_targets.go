@@ -0,0 +1,46 @@
+// internal/config/build_ingest_targets.go
+package config
+
+import (
+	"fmt"
+
+	"MMA2.0/internal/memorycore"
+)
+
+// IngestTargets returns the memories raw frames on a listener may write.
+//
+// Ingest listeners write their configured targets. Any other listener
+// (protocols.raw_frame: true) is scoped to the memories of its own port,
+// so an A55A frame cannot reach memories owned by other listeners.
+// The result is never nil: nil would mean "any memory" to the resolver.
+//
+// Existence of every target is enforced by Validate.
+func IngestTargets(g IngressGate) []memorycore.MemoryID {
+	if !g.IsIngest() {
+		out := make([]memorycore.MemoryID, 0, len(g.Memory))
+		port, err := parseListenPort(g.Listen)
+		if err != nil {
+			return out
+		}
+		for _, def := range g.Memory {
+			out = append(out, memorycore.MemoryID{Port: port, UnitID: def.UnitID})
+		}
+		return out
+	}
+
+	out := make([]memorycore.MemoryID, 0, len(g.Targets))
+	for _, t := range g.Targets {
+		out = append(out, memorycore.MemoryID{Port: t.Port, UnitID: t.UnitID})
+	}
+	return out
+}
+
+// ListenPort returns the port a listener binds (derived from listen).
+// For udp_ingest listeners this is the memory port datagrams write to.
+func ListenPort(g IngressGate) (uint16, error) {
+	port, err := parseListenPort(g.Listen)
+	if err != nil {
+		return 0, fmt.Errorf("invalid listen %q: %w", g.Listen, err)
+	}
+	return port, nil
+}
@@ -3,7 +3,6 @@ package config
 
 import (
 	"fmt"
-	"strings"
 
 	"MMA2.0/internal/memorycore"
 )
@@ -110,43 +109,42 @@ func buildOneMemory(
 		}
 	}
 
+	if def.FileRecords.Files > 0 {
+		layouts.FileRecords = &memorycore.FileLayout{
+			Files:   def.FileRecords.Files,
+			Records: def.FileRecords.Records,
+		}
+	}
+
 	mem, err := memorycore.NewMemory(layouts)
 	if err != nil {
 		return fmt.Errorf("%s: memory create failed: %w", key, err)
 	}
 
 	// --------------------
-	// State Sealing (presence = enabled)
+	// State Sealing (presence = enabled, unless enable: false)
 	// --------------------
-	if def.StateSealing != nil {
-		area := strings.ToLower(strings.TrimSpace(def.StateSealing.Area))
-		if area != "coil" {
-			return fmt.Errorf("%s: state_sealing.area must be 'coil'", key)
-		}
-
-		if def.Coils.Count == 0 {
-			return fmt.Errorf("%s: state_sealing requires coils to be allocated", key)
+	seal, err := resolveStateSealing(def)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	if seal != nil {
+		if err := mem.SetStateSealing(*seal); err != nil {
+			return fmt.Errorf("%s: state_sealing: %w", key, err)
 		}
+	}
 
-		start := def.Coils.Start
-		count := def.Coils.Count
-		addr := def.StateSealing.Address
-
-		endExclusive := uint32(start) + uint32(count)
-		if uint32(addr) < uint32(start) || uint32(addr) >= endExclusive {
-			return fmt.Errorf(
-				"%s: state_sealing.address (%d) out of bounds for coils [%d..%d)",
-				key,
-				addr,
-				start,
-				uint16(endExclusive),
-			)
+	// --------------------
+	// Device identification (optional)
+	// --------------------
+	ident, err := resolveDeviceIdentity(def)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	if ident != nil {
+		if err := mem.SetDeviceIdentity(ident); err != nil {
+			return fmt.Errorf("%s: device_identification: %w", key, err)
 		}
-
-		mem.SetStateSealing(memorycore.StateSealingDef{
-			Area:    memorycore.AreaCoils,
-			Address: addr,
-		})
 	}
 
 	id := memorycore.MemoryID{
@@ -0,0 +1,40 @@
+// internal/config/build_mqtt.go
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// MQTTPassword returns the broker password (inline or first line of password_file).
+func MQTTPassword(b MQTTBroker) (string, error) {
+	if b.PasswordFile == "" {
+		return b.Password, nil
+	}
+
+	data, err := os.ReadFile(b.PasswordFile)
+	if err != nil {
+		return "", fmt.Errorf("password_file: %w", err)
+	}
+
+	line, _, _ := strings.Cut(string(data), "\n")
+	return strings.TrimRight(line, "\r"), nil
+}
+
+// OptionalDuration parses a positive Go duration; "" yields 0 (transport default).
+func OptionalDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("must be > 0")
+	}
+	return d, nil
+}
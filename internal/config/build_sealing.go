@@ -0,0 +1,67 @@
+// internal/config/build_sealing.go
+package config
+
+import (
+	"fmt"
+
+	"MMA2.0/internal/authority"
+	"MMA2.0/internal/memorycore"
+)
+
+// RegisterStateSealing registers every sealing-enabled memory with authority.Sealing.
+//
+// Must run AFTER BuildMemoryStore: lifecycle state is owned by the memory
+// instances in the store, authority only observes and drives it.
+func RegisterStateSealing(cfg *Config, store *memorycore.Store, sealing *authority.Sealing) error {
+	if cfg == nil {
+		return fmt.Errorf("config is nil")
+	}
+	if store == nil || sealing == nil {
+		return fmt.Errorf("store and sealing are required")
+	}
+
+	for li, listener := range cfg.Ingress {
+		if len(listener.Memory) == 0 {
+			continue
+		}
+
+		port, err := parseListenPort(listener.Listen)
+		if err != nil {
+			return fmt.Errorf("listeners[%d] (%s) listen=%q: %w", li, listener.ID, listener.Listen, err)
+		}
+
+		for mi, def := range listener.Memory {
+			seal, err := resolveStateSealing(def)
+			if err != nil {
+				return fmt.Errorf("listeners[%d] (%s).memory[%d]: %w", li, listener.ID, mi, err)
+			}
+			if seal == nil {
+				continue
+			}
+
+			mid := memorycore.MemoryID{
+				Port:   port,
+				UnitID: def.UnitID,
+			}
+
+			mem, err := store.MustGet(mid)
+			if err != nil {
+				return fmt.Errorf(
+					"listeners[%d] (%s).memory[%d] (port=%d unit=%d): state sealing: %w",
+					li, listener.ID, mi, mid.Port, mid.UnitID, err,
+				)
+			}
+
+			if mem.StateSealing() == nil {
+				return fmt.Errorf(
+					"listeners[%d] (%s).memory[%d] (port=%d unit=%d): state sealing not attached to memory",
+					li, listener.ID, mi, mid.Port, mid.UnitID,
+				)
+			}
+
+			sealing.Register(mid, mem)
+		}
+	}
+
+	return nil
+}
@@ -1,11 +1,32 @@
 // internal/config/config.go
 package config
 
+import (
+	"strings"
+
+	"MMA2.0/internal/memorycore"
+)
+
 // Config is the root configuration for MMA2.
 // It describes structure only, not behavior.
 type Config struct {
 	Ingress []IngressGate `yaml:"listeners"`
 	Memory  MemoryConfig `yaml:"memory"`
+
+	// Optional write-only HTTP/JSON ingest listeners.
+	REST []RESTListener `yaml:"rest"`
+
+	// Optional MQTT 3.1.1 ingest clients (one per broker).
+	MQTT []MQTTBroker `yaml:"mqtt"`
+
+	// Optional exec adapters (driver processes writing JSON lines).
+	Exec []ExecAdapter `yaml:"exec"`
+
+	// Optional Modbus TCP pollers (MMA as master, filling local memory).
+	Pollers []PollerDevice `yaml:"pollers"`
+
+	// Optional write-back subscriptions (Modbus writes pushed downstream).
+	Egress []EgressSubscription `yaml:"egress"`
 }
 
 // --------------------
@@ -22,10 +43,314 @@ type IngressGate struct {
 	ID     string `yaml:"id"`
 	Listen string `yaml:"listen"`
 
+	// Optional listener kind: "modbus" (default) | "ingest" | "udp_ingest".
+	// An ingest listener owns no memory and serves raw frames only;
+	// frames select their memory by explicit (port, unit_id) from Targets.
+	// A udp_ingest listener owns no memory and accepts one raw ingest
+	// packet per datagram for the memories of its listen port.
+	Kind string `yaml:"kind"`
+
+	// Targets lists the memories an ingest listener may write.
+	// Required for kind: ingest, forbidden otherwise.
+	Targets []IngestTarget `yaml:"targets"`
+
+	// Reply enables status datagrams on a udp_ingest listener.
+	// Absent/false = fire-and-forget.
+	Reply bool `yaml:"reply"`
+
+	// Optional ingress firewall (CIDR or bare IP).
+	// Evaluated per connection BEFORE protocol classification.
+	// Deny wins; if allow is non-empty, the source must match it.
+	Allow []string `yaml:"allow"`
+	Deny  []string `yaml:"deny"`
+
+	// Optional protocol enablement.
+	// Absent = all protocols enabled (legacy behavior).
+	// Present = only protocols set to true are served.
+	Protocols *ProtocolsConfig `yaml:"protocols"`
+
+	// DiscardUnknown closes connections that match no protocol
+	// instead of falling back to Modbus.
+	DiscardUnknown bool `yaml:"discard_unknown"`
+
+	// Optional shared keys for authenticated ingest.
+	// Applies to every nested memory that does not declare its own ingest_auth.
+	IngestAuth *IngestAuthConfig `yaml:"ingest_auth"`
+
+	// Optional protocol pin: "modbus" | "raw_ingest" | "raw_frame".
+	// When set, classification (sniffing) is skipped entirely.
+	Protocol string `yaml:"protocol"`
+
+	// Optional classification timeout (Go duration, e.g. "5s").
+	// Connections that send nothing within it are closed.
+	ClassifyTimeout string `yaml:"classify_timeout"`
+
+	// Optional hard cap for one raw frame (header + payload), in bytes.
+	// 0 = transport default.
+	MaxPacketBytes int `yaml:"max_packet_bytes"`
+
+	// Optional Modbus gateway routes: unit IDs without local memory
+	// forwarded to backend Modbus TCP devices (after authority).
+	Gateway []GatewayRoute `yaml:"gateway"`
+
 	// Optional nested memory definitions (NEW MODEL)
 	Memory []MemoryDefinition `yaml:"memory"`
 }
 
+// ProtocolsConfig enables protocols on a single ingress gate.
+// A connection classified as a disabled protocol is closed.
+//
+// raw_frame (A55A, explicit Port/UnitID) is never enabled implicitly
+// and must be opted in. Frames are scoped to the listener's own memories
+// (see IngestTargets).
+type ProtocolsConfig struct {
+	Modbus    bool `yaml:"modbus"`
+	RawIngest bool `yaml:"raw_ingest"`
+	RawFrame  bool `yaml:"raw_frame"`
+}
+
+// GatewayRoute forwards one unit ID of a Modbus listener to a backend device.
+// Authority applies to the route as to a memory: (listen port, unit_id).
+type GatewayRoute struct {
+	UnitID  uint16 `yaml:"unit_id"`
+	Address string `yaml:"address"` // backend host:port
+
+	// Optional backend unit ID (default = unit_id).
+	RemoteUnitID *uint16 `yaml:"remote_unit_id"`
+
+	// Optional per-exchange timeout (Go duration, default "1s").
+	Timeout string `yaml:"timeout"`
+
+	// Access rules for the routed unit (absent = default deny).
+	Policy *MemoryPolicyConfig `yaml:"policy"`
+}
+
+// IngestTarget identifies one memory writable through an ingest listener.
+type IngestTarget struct {
+	Port   uint16 `yaml:"port"`
+	UnitID uint16 `yaml:"unit_id"`
+}
+
+// Listener kinds.
+const (
+	KindModbus    = "modbus"
+	KindIngest    = "ingest"
+	KindUDPIngest = "udp_ingest"
+)
+
+// IsIngest reports whether this gate is a dedicated ingest listener.
+func (g IngressGate) IsIngest() bool {
+	return strings.EqualFold(strings.TrimSpace(g.Kind), KindIngest)
+}
+
+// IsUDPIngest reports whether this gate is a UDP raw ingest listener.
+func (g IngressGate) IsUDPIngest() bool {
+	return strings.EqualFold(strings.TrimSpace(g.Kind), KindUDPIngest)
+}
+
+// ModbusEnabled reports whether Modbus is served on this gate.
+// Never on ingest listeners.
+func (g IngressGate) ModbusEnabled() bool {
+	if g.IsIngest() {
+		return false
+	}
+	return g.Protocols == nil || g.Protocols.Modbus
+}
+
+// RawIngestEnabled reports whether raw ingest is served on this gate.
+// Never on ingest listeners (raw ingest derives its port from the socket).
+func (g IngressGate) RawIngestEnabled() bool {
+	if g.IsIngest() {
+		return false
+	}
+	return g.Protocols == nil || g.Protocols.RawIngest
+}
+
+// RawFrameEnabled reports whether A55A raw frames are served on this gate.
+// Always on ingest listeners; otherwise requires protocols.raw_frame: true.
+func (g IngressGate) RawFrameEnabled() bool {
+	if g.IsIngest() {
+		return true
+	}
+	return g.Protocols != nil && g.Protocols.RawFrame
+}
+
+// --------------------
+// REST ingest
+// --------------------
+
+// RESTListener defines a write-only HTTP/JSON ingest listener.
+// Requests name their target memory explicitly (port, unit_id),
+// so a REST listener owns no memory.
+type RESTListener struct {
+	ID     string `yaml:"id"`
+	Listen string `yaml:"listen"`
+
+	// Optional endpoint path (default "/write").
+	Path string `yaml:"path"`
+
+	// Optional body size cap in bytes (0 = transport default).
+	MaxBodyBytes int64 `yaml:"max_body_bytes"`
+
+	// Optional firewall (CIDR or bare IP), same semantics as listeners.
+	Allow []string `yaml:"allow"`
+	Deny  []string `yaml:"deny"`
+}
+
+// --------------------
+// MQTT ingest
+// --------------------
+
+// MQTTBroker defines one MQTT ingest client connection.
+// The client only subscribes; it never publishes memory state.
+type MQTTBroker struct {
+	ID       string `yaml:"id"`
+	Broker   string `yaml:"broker"` // host:port
+	ClientID string `yaml:"client_id"`
+
+	// Optional credentials. password_file takes the first line of a file.
+	Username     string `yaml:"username"`
+	Password     string `yaml:"password"`
+	PasswordFile string `yaml:"password_file"`
+
+	// Optional keep-alive (Go duration, default "30s").
+	KeepAlive string `yaml:"keep_alive"`
+
+	// Optional clean session flag (default false = persistent session,
+	// so QoS 1 messages queued while disconnected are delivered).
+	CleanSession bool `yaml:"clean_session"`
+
+	// Optional reconnect backoff bounds (Go durations, defaults "1s" / "1m").
+	MinBackoff string `yaml:"min_backoff"`
+	MaxBackoff string `yaml:"max_backoff"`
+
+	Subscriptions []MQTTSubscription `yaml:"subscriptions"`
+}
+
+// MQTTSubscription is one topic filter and its payload format.
+type MQTTSubscription struct {
+	Topic string `yaml:"topic"`
+	QoS   byte   `yaml:"qos"` // 0 | 1
+
+	// Payload format: "json" (explicit port/unit_id/...) | "raw" (raw ingest packet).
+	Format string `yaml:"format"`
+
+	// Target port for format: raw (raw packets carry no port). Forbidden for json.
+	Port uint16 `yaml:"port"`
+}
+
+// --------------------
+// Exec ingest
+// --------------------
+
+// ExecAdapter defines one supervised driver process.
+// Its stdout carries one JSON write request per line (REST schema);
+// failures are reported back on its stdin. Writes use source IP 127.0.0.1
+// for ingest_policy evaluation.
+type ExecAdapter struct {
+	ID      string   `yaml:"id"`
+	Command []string `yaml:"command"` // argv
+	Dir     string   `yaml:"dir"`
+	Env     []string `yaml:"env"` // KEY=VALUE, added to the parent environment
+
+	// Optional restart backoff bounds (Go durations, defaults "1s" / "1m").
+	MinBackoff string `yaml:"min_backoff"`
+	MaxBackoff string `yaml:"max_backoff"`
+}
+
+// --------------------
+// Pollers
+// --------------------
+
+// PollerDevice defines one downstream Modbus TCP device polled by MMA.
+// Results are written into the target memory (which must exist).
+type PollerDevice struct {
+	ID      string `yaml:"id"`
+	Address string `yaml:"address"` // host:port
+	UnitID  uint16 `yaml:"unit_id"` // remote unit id (0..255)
+
+	// Optional schedule (Go durations, defaults "1s" / "1s").
+	Interval string `yaml:"interval"`
+	Timeout  string `yaml:"timeout"`
+
+	// Optional extra attempts per read within one cycle (default 0).
+	Retries int `yaml:"retries"`
+
+	Target IngestTarget `yaml:"target"`
+	Reads  []PollerRead `yaml:"reads"`
+
+	// Optional health block (3 registers: status, consecutive failures,
+	// last exception code) in the target memory.
+	Status *PollerStatus `yaml:"status"`
+}
+
+// PollerRead is one polled range.
+// The local area follows the function code (1 coils, 2 discrete inputs,
+// 3 holding registers, 4 input registers).
+type PollerRead struct {
+	Function uint8  `yaml:"function"`
+	Address  uint16 `yaml:"address"`
+	Count    uint16 `yaml:"count"`
+
+	// Optional local address (default = remote address).
+	LocalAddress *uint16 `yaml:"local_address"`
+}
+
+// PollerStatus locates the poller health block.
+type PollerStatus struct {
+	Area    string `yaml:"area"` // holding_registers | input_registers
+	Address uint16 `yaml:"address"`
+
+	// ResolvedArea is Area resolved by Validate.
+	ResolvedArea memorycore.Area `yaml:"-"`
+}
+
+// --------------------
+// Egress
+// --------------------
+
+// EgressSubscription pushes Modbus client writes that touch a range of
+// a memory to one sink. Only writes from Modbus clients are propagated
+// (not ingest, pollers or sealing).
+type EgressSubscription struct {
+	ID     string       `yaml:"id"`
+	Source IngestTarget `yaml:"source"` // memory (port, unit_id), must exist
+
+	Area  string `yaml:"area"` // coils | holding_registers
+	Start uint16 `yaml:"start"`
+	Count uint16 `yaml:"count"`
+
+	// ResolvedArea is Area resolved by Validate.
+	ResolvedArea memorycore.Area `yaml:"-"`
+
+	// Optional delivery bounds: pending events (default 256, overflow is
+	// dropped and counted) and extra attempts per event (default 2).
+	QueueSize int  `yaml:"queue_size"`
+	Retries   *int `yaml:"retries"`
+
+	Sink EgressSink `yaml:"sink"`
+}
+
+// EgressSink selects where change events are delivered.
+//
+//	tcp:     raw ingest v2 frames to address (host:port), one reply per frame
+//	webhook: HTTP POST of a JSON write request to url
+//	modbus:  FC15/FC16 writes to a Modbus TCP device at address
+type EgressSink struct {
+	Type    string `yaml:"type"`
+	Address string `yaml:"address"`
+	URL     string `yaml:"url"`
+
+	// tcp, modbus: remote unit id (default = source unit_id).
+	UnitID *uint16 `yaml:"unit_id"`
+
+	// tcp, modbus: remote address of the subscription start (default = start).
+	RemoteAddress *uint16 `yaml:"remote_address"`
+
+	// Optional per-attempt timeout (Go duration, default "5s").
+	Timeout string `yaml:"timeout"`
+}
+
 // --------------------
 // Memory (LEGACY / CANONICAL RUNTIME MODEL)
 // --------------------
@@ -45,12 +370,49 @@ type MemoryDefinition struct {
 	HoldingRegs    Area `yaml:"holding_registers"`
 	InputRegs      Area `yaml:"input_registers"`
 
+	// Optional file-record area (Modbus FC20/21).
+	FileRecords FileRecordsArea `yaml:"file_records"`
+
 	// Optional state sealing configuration.
-	// Presence = enabled.
+	// Presence = enabled, unless enable: false.
 	StateSealing *StateSealingConfig `yaml:"state_sealing"`
 
 	// Optional per-memory authorization policy
 	Policy *MemoryPolicyConfig `yaml:"policy"`
+
+	// Optional per-memory ingest authorization policy.
+	// Absent = ingest allowed from any source (legacy behavior).
+	IngestPolicy *IngestPolicyConfig `yaml:"ingest_policy"`
+
+	// Optional shared keys for authenticated ingest (overrides listener ingest_auth).
+	// Present = unauthenticated ingest writes are rejected.
+	IngestAuth *IngestAuthConfig `yaml:"ingest_auth"`
+
+	// Optional Modbus device identification (FC43 / MEI 0x0E).
+	DeviceIdentification *DeviceIdentificationConfig `yaml:"device_identification"`
+}
+
+// DeviceIdentificationConfig declares the identification objects of a memory.
+// vendor_name, product_code and revision (basic) are required;
+// the other standard objects are regular; private objects are extended.
+type DeviceIdentificationConfig struct {
+	VendorName  string `yaml:"vendor_name"`
+	ProductCode string `yaml:"product_code"`
+	Revision    string `yaml:"revision"` // MajorMinorRevision
+
+	VendorURL           string `yaml:"vendor_url"`
+	ProductName         string `yaml:"product_name"`
+	ModelName           string `yaml:"model_name"`
+	UserApplicationName string `yaml:"user_application_name"`
+
+	// Private objects (id 0x80..0xFF).
+	Private []DeviceObjectConfig `yaml:"private"`
+}
+
+// DeviceObjectConfig is one private identification object.
+type DeviceObjectConfig struct {
+	ID    uint8  `yaml:"id"`
+	Value string `yaml:"value"`
 }
 
 type Area struct {
@@ -58,16 +420,52 @@ type Area struct {
 	Count uint16 `yaml:"count"`
 }
 
+// FileRecordsArea declares files numbered 1..files, each of `records`
+// 16-bit records (0..records-1). Absent (all zero) = no file records.
+//
+// Ingest and ingest_policy address the area flat, as area "file_records":
+//
+//	address = (file-1)*records + record
+type FileRecordsArea struct {
+	Files   uint16 `yaml:"files"`
+	Records uint16 `yaml:"records"`
+}
+
 // --------------------
 // State Sealing
 // --------------------
 
 // StateSealingConfig defines where the sealing flag lives.
-// Semantics:
-//   0 = sealed
-//   1 = unsealed
+//
+// Canonical form (docs/01_STATE_SEALING.md):
+//   state_sealing:
+//     enable: true
+//     flag_location:
+//       area: discrete_inputs
+//       address: 0
+//
+// Shorthand (presence = enabled):
+//   state_sealing:
+//     area: coil
+//     address: 0
+//
+// enable: false behaves exactly like the block being absent.
+//
+// Semantics (PRE-RUN only, written by ingest):
+//   0 = no effect
+//   1 = unlock (PRE-RUN -> RUN)
 type StateSealingConfig struct {
-	Area    string `yaml:"area"`    // "coil" (only supported value for now)
+	Enable       *bool              `yaml:"enable"`
+	FlagLocation *FlagLocationConfig `yaml:"flag_location"`
+
+	// Shorthand flag location (mutually exclusive with flag_location)
+	Area    string `yaml:"area"`    // "coil" | "discrete_inputs"
+	Address uint16 `yaml:"address"`
+}
+
+// FlagLocationConfig is the address of the state sealing flag.
+type FlagLocationConfig struct {
+	Area    string `yaml:"area"`    // "coil" | "discrete_inputs"
 	Address uint16 `yaml:"address"`
 }
 
@@ -90,3 +488,36 @@ type PolicyRuleConfig struct {
 	// Allowed Modbus function codes for this rule.
 	AllowFC []uint8 `yaml:"allow_fc"`
 }
+
+// IngestPolicyConfig declares which sources may write which ranges via ingest.
+// Rules are evaluated top-down; first match wins; default deny if none match.
+type IngestPolicyConfig struct {
+	Rules []IngestRuleConfig `yaml:"rules"`
+}
+
+type IngestRuleConfig struct {
+	ID string `yaml:"id"`
+
+	// CIDR or bare IP strings. Bare IPs are treated as /32 (IPv4) or /128 (IPv6).
+	SourceIP []string `yaml:"source_ip"`
+
+	// Writable ranges for this rule.
+	Allow []IngestRangeConfig `yaml:"allow"`
+}
+
+// IngestRangeConfig is an explicit writable window.
+type IngestRangeConfig struct {
+	Area  string `yaml:"area"` // coils | discrete_inputs | holding_registers | input_registers
+	Start uint16 `yaml:"start"`
+	Count uint16 `yaml:"count"`
+}
+
+// IngestAuthConfig declares HMAC-SHA256 keys for authenticated ingest.
+// Keys are hex-encoded, at least 16 bytes.
+// Listing two keys at once allows rotation (either key verifies).
+type IngestAuthConfig struct {
+	Keys []string `yaml:"keys"`
+
+	// Files containing one hex-encoded key each (surrounding whitespace ignored).
+	KeyFiles []string `yaml:"key_files"`
+}
@@ -0,0 +1,76 @@
+// internal/config/device_identification.go
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"MMA2.0/internal/memorycore"
+)
+
+// maxDeviceObjectLen keeps every object within one FC43 response PDU
+// (253 bytes - 7 header bytes - object id and length).
+const maxDeviceObjectLen = 244
+
+// resolveDeviceIdentity translates the device_identification block into
+// a runtime identity. Returns nil (no error) when the block is absent.
+//
+// Errors are relative to the memory definition (prefix with the memory key).
+func resolveDeviceIdentity(def MemoryDefinition) (*memorycore.DeviceIdentity, error) {
+	di := def.DeviceIdentification
+	if di == nil {
+		return nil, nil
+	}
+
+	objects := make(map[uint8][]byte)
+
+	standard := []struct {
+		id    uint8
+		name  string
+		value string
+		basic bool
+	}{
+		{memorycore.DeviceObjectVendorName, "vendor_name", di.VendorName, true},
+		{memorycore.DeviceObjectProductCode, "product_code", di.ProductCode, true},
+		{memorycore.DeviceObjectRevision, "revision", di.Revision, true},
+		{memorycore.DeviceObjectVendorURL, "vendor_url", di.VendorURL, false},
+		{memorycore.DeviceObjectProductName, "product_name", di.ProductName, false},
+		{memorycore.DeviceObjectModelName, "model_name", di.ModelName, false},
+		{memorycore.DeviceObjectUserApplicationName, "user_application_name", di.UserApplicationName, false},
+	}
+
+	for _, o := range standard {
+		if strings.TrimSpace(o.value) == "" {
+			if o.basic {
+				return nil, fmt.Errorf("device_identification.%s is required", o.name)
+			}
+			continue
+		}
+		if err := checkDeviceObject(o.value); err != nil {
+			return nil, fmt.Errorf("device_identification.%s: %w", o.name, err)
+		}
+		objects[o.id] = []byte(o.value)
+	}
+
+	for i, p := range di.Private {
+		if p.ID < memorycore.DeviceObjectFirstPrivate {
+			return nil, fmt.Errorf("device_identification.private[%d].id: must be 0x80..0xFF, got 0x%02X", i, p.ID)
+		}
+		if _, ok := objects[p.ID]; ok {
+			return nil, fmt.Errorf("device_identification.private[%d].id: duplicate id 0x%02X", i, p.ID)
+		}
+		if err := checkDeviceObject(p.Value); err != nil {
+			return nil, fmt.Errorf("device_identification.private[%d].value: %w", i, err)
+		}
+		objects[p.ID] = []byte(p.Value)
+	}
+
+	return &memorycore.DeviceIdentity{Objects: objects}, nil
+}
+
+func checkDeviceObject(v string) error {
+	if len(v) > maxDeviceObjectLen {
+		return fmt.Errorf("longer than %d bytes", maxDeviceObjectLen)
+	}
+	return nil
+}
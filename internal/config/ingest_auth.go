@@ -0,0 +1,59 @@
+// internal/config/ingest_auth.go
+package config
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// minIngestKeyLen is the minimum accepted HMAC key length in bytes.
+const minIngestKeyLen = 16
+
+// loadIngestKeys decodes inline keys and reads key files.
+// Returns nil when c is nil.
+func loadIngestKeys(c *IngestAuthConfig) ([][]byte, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	keys := make([][]byte, 0, len(c.Keys)+len(c.KeyFiles))
+
+	for i, s := range c.Keys {
+		k, err := decodeIngestKey(s)
+		if err != nil {
+			return nil, fmt.Errorf("ingest_auth.keys[%d]: %w", i, err)
+		}
+		keys = append(keys, k)
+	}
+
+	for i, path := range c.KeyFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("ingest_auth.key_files[%d]: %w", i, err)
+		}
+		k, err := decodeIngestKey(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("ingest_auth.key_files[%d] (%s): %w", i, path, err)
+		}
+		keys = append(keys, k)
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("ingest_auth: at least one key is required")
+	}
+
+	return keys, nil
+}
+
+func decodeIngestKey(s string) ([]byte, error) {
+	k, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("key must be hex-encoded: %v", err)
+	}
+	if len(k) < minIngestKeyLen {
+		return nil, fmt.Errorf("key must be at least %d bytes", minIngestKeyLen)
+	}
+	return k, nil
+}
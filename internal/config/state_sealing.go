@@ -0,0 +1,83 @@
+// internal/config/state_sealing.go
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"MMA2.0/internal/memorycore"
+)
+
+// resolveStateSealing translates the state_sealing block into a runtime definition.
+//
+// Returns nil (no error) when state sealing is disabled:
+//   - block absent
+//   - enable: false
+//
+// Errors are relative to the memory definition (prefix with the memory key).
+func resolveStateSealing(def MemoryDefinition) (*memorycore.StateSealingDef, error) {
+	ss := def.StateSealing
+	if ss == nil {
+		return nil, nil
+	}
+
+	// enable: false == block absent
+	if ss.Enable != nil && !*ss.Enable {
+		return nil, nil
+	}
+
+	shorthand := strings.TrimSpace(ss.Area) != ""
+
+	var areaName string
+	var address uint16
+	var path string
+
+	switch {
+	case ss.FlagLocation != nil && shorthand:
+		return nil, fmt.Errorf("state_sealing: flag_location and shorthand area/address are mutually exclusive")
+
+	case ss.FlagLocation != nil:
+		areaName = ss.FlagLocation.Area
+		address = ss.FlagLocation.Address
+		path = "state_sealing.flag_location"
+
+	case shorthand:
+		areaName = ss.Area
+		address = ss.Address
+		path = "state_sealing"
+
+	default:
+		return nil, fmt.Errorf("state_sealing.flag_location is required")
+	}
+
+	var area memorycore.Area
+	var layout Area
+
+	switch strings.ToLower(strings.TrimSpace(areaName)) {
+	case "coil", "coils":
+		area = memorycore.AreaCoils
+		layout = def.Coils
+	case "discrete_inputs":
+		area = memorycore.AreaDiscreteInputs
+		layout = def.DiscreteInputs
+	default:
+		return nil, fmt.Errorf("%s.area must be 'coil' or 'discrete_inputs'", path)
+	}
+
+	if layout.Count == 0 {
+		return nil, fmt.Errorf("%s requires %s to be allocated", path, area)
+	}
+
+	endExclusive := uint32(layout.Start) + uint32(layout.Count)
+	if uint32(address) < uint32(layout.Start) || uint32(address) >= endExclusive {
+		return nil, fmt.Errorf(
+			"%s.address (%d) out of bounds for %s [%d..%d)",
+			path, address, area, layout.Start, endExclusive,
+		)
+	}
+
+	return &memorycore.StateSealingDef{
+		Area:    area,
+		Address: address,
+	}, nil
+}
@@ -2,8 +2,13 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"net/netip"
+	"net/url"
 	"strings"
+	"time"
+
+	"MMA2.0/internal/memorycore"
 )
 
 // Validate performs structural validation on the loaded configuration.
@@ -23,8 +28,39 @@ func Validate(cfg *Config) error {
 		return err
 	}
 
+	if err := validateREST(cfg.REST); err != nil {
+		return err
+	}
+	if err := validateMQTT(cfg.MQTT); err != nil {
+		return err
+	}
+	if err := validateExec(cfg.Exec); err != nil {
+		return err
+	}
+
 	// Validate memory definitions from BOTH sources and enforce identity consistency.
-	if err := validateAllMemories(cfg); err != nil {
+	memories, err := validateAllMemories(cfg)
+	if err != nil {
+		return err
+	}
+
+	// Ingest listener targets must name configured memories.
+	if err := validateIngestTargets(cfg.Ingress, memories); err != nil {
+		return err
+	}
+
+	// Gateway unit IDs must not shadow local memories.
+	if err := validateGatewayRoutes(cfg.Ingress, memories); err != nil {
+		return err
+	}
+
+	// Pollers write into configured memories, within their areas.
+	if err := validatePollers(cfg.Pollers, memoryDefinitions(cfg)); err != nil {
+		return err
+	}
+
+	// Egress subscriptions watch ranges of configured memories.
+	if err := validateEgress(cfg.Egress, memoryDefinitions(cfg)); err != nil {
 		return err
 	}
 
@@ -52,6 +88,73 @@ func validateIngress(gates []IngressGate) error {
 			return fmt.Errorf("listeners[%d]: listen is required", i)
 		}
 
+		switch strings.ToLower(strings.TrimSpace(g.Kind)) {
+		case "", KindModbus:
+			if len(g.Targets) > 0 {
+				return fmt.Errorf("listeners[%d] (%s).targets: only allowed on kind: ingest", i, g.ID)
+			}
+			if g.Reply {
+				return fmt.Errorf("listeners[%d] (%s).reply: only allowed on kind: udp_ingest", i, g.ID)
+			}
+		case KindIngest:
+			if err := validateIngestListener(i, g); err != nil {
+				return err
+			}
+		case KindUDPIngest:
+			if err := validateUDPIngestListener(i, g); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("listeners[%d] (%s).kind: must be 'modbus', 'ingest' or 'udp_ingest', got %q", i, g.ID, g.Kind)
+		}
+
+		if err := validateCIDRList(fmt.Sprintf("listeners[%d] (%s).allow", i, g.ID), g.Allow); err != nil {
+			return err
+		}
+		if err := validateCIDRList(fmt.Sprintf("listeners[%d] (%s).deny", i, g.ID), g.Deny); err != nil {
+			return err
+		}
+
+		if _, err := loadIngestKeys(g.IngestAuth); err != nil {
+			return fmt.Errorf("listeners[%d] (%s).%w", i, g.ID, err)
+		}
+
+		if g.Protocols != nil && !g.Protocols.Modbus && !g.Protocols.RawIngest && !g.Protocols.RawFrame {
+			return fmt.Errorf("listeners[%d] (%s).protocols: at least one protocol must be enabled", i, g.ID)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(g.Protocol)) {
+		case "":
+		case "modbus":
+			if !g.ModbusEnabled() {
+				return fmt.Errorf("listeners[%d] (%s).protocol: modbus is pinned but disabled in protocols", i, g.ID)
+			}
+		case "raw_ingest":
+			if !g.RawIngestEnabled() {
+				return fmt.Errorf("listeners[%d] (%s).protocol: raw_ingest is pinned but disabled in protocols", i, g.ID)
+			}
+		case "raw_frame":
+			if !g.RawFrameEnabled() {
+				return fmt.Errorf("listeners[%d] (%s).protocol: raw_frame is pinned but not enabled in protocols", i, g.ID)
+			}
+		default:
+			return fmt.Errorf("listeners[%d] (%s).protocol: must be 'modbus', 'raw_ingest' or 'raw_frame', got %q", i, g.ID, g.Protocol)
+		}
+
+		if g.MaxPacketBytes < 0 {
+			return fmt.Errorf("listeners[%d] (%s).max_packet_bytes: must be >= 0", i, g.ID)
+		}
+
+		if strings.TrimSpace(g.ClassifyTimeout) != "" {
+			d, err := time.ParseDuration(strings.TrimSpace(g.ClassifyTimeout))
+			if err != nil {
+				return fmt.Errorf("listeners[%d] (%s).classify_timeout: %v", i, g.ID, err)
+			}
+			if d <= 0 {
+				return fmt.Errorf("listeners[%d] (%s).classify_timeout: must be > 0", i, g.ID)
+			}
+		}
+
 		// If nested memories exist, the port must be parseable
 		if len(g.Memory) > 0 {
 			if _, err := parseListenPort(g.Listen); err != nil {
@@ -75,18 +178,20 @@ type memIdentity struct {
 	unit uint16
 }
 
-func validateAllMemories(cfg *Config) error {
+// validateAllMemories returns every configured memory identity
+// with the config path that defines it.
+func validateAllMemories(cfg *Config) (map[memIdentity]string, error) {
 	seen := make(map[memIdentity]string)
 
 	// 1) Legacy model
 	for key, def := range cfg.Memory.Memories {
 		if err := validateLegacyMemoryDef(key, def); err != nil {
-			return err
+			return nil, err
 		}
 
 		id := memIdentity{port: def.Port, unit: def.UnitID}
 		if prev, ok := seen[id]; ok {
-			return fmt.Errorf(
+			return nil, fmt.Errorf(
 				"memory identity conflict: (port=%d unit=%d) defined in %s and memory[%s]",
 				id.port, id.unit, prev, key,
 			)
@@ -102,7 +207,7 @@ func validateAllMemories(cfg *Config) error {
 
 		port, err := parseListenPort(l.Listen)
 		if err != nil {
-			return fmt.Errorf(
+			return nil, fmt.Errorf(
 				"listeners[%d] (%s): invalid listen %q: %w",
 				li, l.ID, l.Listen, err,
 			)
@@ -110,14 +215,14 @@ func validateAllMemories(cfg *Config) error {
 
 		for mi, def := range l.Memory {
 			if err := validateNestedMemoryDef(li, mi, l.ID, port, def); err != nil {
-				return err
+				return nil, err
 			}
 
 			id := memIdentity{port: port, unit: def.UnitID}
 			path := fmt.Sprintf("listeners[%d](%s).memory[%d]", li, l.ID, mi)
 
 			if prev, ok := seen[id]; ok {
-				return fmt.Errorf(
+				return nil, fmt.Errorf(
 					"memory identity conflict: (port=%d unit=%d) defined in %s and %s",
 					id.port, id.unit, prev, path,
 				)
@@ -126,9 +231,546 @@ func validateAllMemories(cfg *Config) error {
 		}
 	}
 
+	return seen, nil
+}
+
+// --------------------
+// REST validation
+// --------------------
+
+func validateREST(listeners []RESTListener) error {
+	seen := make(map[string]struct{})
+
+	for i, l := range listeners {
+		if l.ID == "" {
+			return fmt.Errorf("rest[%d]: id is required", i)
+		}
+		if _, ok := seen[l.ID]; ok {
+			return fmt.Errorf("rest[%d]: duplicate id %q", i, l.ID)
+		}
+		seen[l.ID] = struct{}{}
+
+		if strings.TrimSpace(l.Listen) == "" {
+			return fmt.Errorf("rest[%d] (%s): listen is required", i, l.ID)
+		}
+		if l.Path != "" && !strings.HasPrefix(l.Path, "/") {
+			return fmt.Errorf("rest[%d] (%s).path: must start with '/', got %q", i, l.ID, l.Path)
+		}
+		if l.MaxBodyBytes < 0 {
+			return fmt.Errorf("rest[%d] (%s).max_body_bytes: must be >= 0", i, l.ID)
+		}
+
+		if err := validateCIDRList(fmt.Sprintf("rest[%d] (%s).allow", i, l.ID), l.Allow); err != nil {
+			return err
+		}
+		if err := validateCIDRList(fmt.Sprintf("rest[%d] (%s).deny", i, l.ID), l.Deny); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// --------------------
+// MQTT validation
+// --------------------
+
+func validateMQTT(brokers []MQTTBroker) error {
+	seen := make(map[string]struct{})
+
+	for i, b := range brokers {
+		if b.ID == "" {
+			return fmt.Errorf("mqtt[%d]: id is required", i)
+		}
+		if _, ok := seen[b.ID]; ok {
+			return fmt.Errorf("mqtt[%d]: duplicate id %q", i, b.ID)
+		}
+		seen[b.ID] = struct{}{}
+
+		path := fmt.Sprintf("mqtt[%d] (%s)", i, b.ID)
+
+		if _, _, err := net.SplitHostPort(b.Broker); err != nil {
+			return fmt.Errorf("%s.broker: expected host:port, got %q", path, b.Broker)
+		}
+		if strings.TrimSpace(b.ClientID) == "" {
+			return fmt.Errorf("%s.client_id is required", path)
+		}
+		if b.Password != "" && b.PasswordFile != "" {
+			return fmt.Errorf("%s: password and password_file are mutually exclusive", path)
+		}
+		// MQTT 3.1.1 §3.1.2.9: the password flag requires the user name flag.
+		if (b.Password != "" || b.PasswordFile != "") && b.Username == "" {
+			return fmt.Errorf("%s: password requires username", path)
+		}
+		if _, err := MQTTPassword(b); err != nil {
+			return fmt.Errorf("%s.%w", path, err)
+		}
+
+		for _, d := range []struct{ name, v string }{
+			{"keep_alive", b.KeepAlive},
+			{"min_backoff", b.MinBackoff},
+			{"max_backoff", b.MaxBackoff},
+		} {
+			if _, err := OptionalDuration(d.v); err != nil {
+				return fmt.Errorf("%s.%s: %v", path, d.name, err)
+			}
+		}
+
+		// CONNECT carries keep-alive in whole seconds (uint16); 0 would
+		// mean "disabled" while the client still pings.
+		if ka, _ := OptionalDuration(b.KeepAlive); ka != 0 && (ka < time.Second || ka > 65535*time.Second) {
+			return fmt.Errorf("%s.keep_alive: must be between 1s and 65535s, got %s", path, ka)
+		}
+
+		if len(b.Subscriptions) == 0 {
+			return fmt.Errorf("%s.subscriptions: at least one subscription is required", path)
+		}
+		for si, s := range b.Subscriptions {
+			spath := fmt.Sprintf("%s.subscriptions[%d]", path, si)
+
+			if strings.TrimSpace(s.Topic) == "" {
+				return fmt.Errorf("%s.topic is required", spath)
+			}
+			if s.QoS > 1 {
+				return fmt.Errorf("%s.qos: must be 0 or 1, got %d", spath, s.QoS)
+			}
+
+			switch strings.ToLower(strings.TrimSpace(s.Format)) {
+			case "json":
+				if s.Port != 0 {
+					return fmt.Errorf("%s.port: not allowed for format json (the payload names its port)", spath)
+				}
+			case "raw":
+				if s.Port == 0 {
+					return fmt.Errorf("%s.port: required for format raw", spath)
+				}
+			default:
+				return fmt.Errorf("%s.format: must be 'json' or 'raw', got %q", spath, s.Format)
+			}
+		}
+	}
+
+	return nil
+}
+
+// --------------------
+// Exec validation
+// --------------------
+
+func validateExec(adapters []ExecAdapter) error {
+	seen := make(map[string]struct{})
+
+	for i, a := range adapters {
+		if a.ID == "" {
+			return fmt.Errorf("exec[%d]: id is required", i)
+		}
+		if _, ok := seen[a.ID]; ok {
+			return fmt.Errorf("exec[%d]: duplicate id %q", i, a.ID)
+		}
+		seen[a.ID] = struct{}{}
+
+		path := fmt.Sprintf("exec[%d] (%s)", i, a.ID)
+
+		if len(a.Command) == 0 || strings.TrimSpace(a.Command[0]) == "" {
+			return fmt.Errorf("%s.command is required", path)
+		}
+		for ei, e := range a.Env {
+			if k, _, ok := strings.Cut(e, "="); !ok || k == "" {
+				return fmt.Errorf("%s.env[%d]: expected KEY=VALUE, got %q", path, ei, e)
+			}
+		}
+		if _, err := OptionalDuration(a.MinBackoff); err != nil {
+			return fmt.Errorf("%s.min_backoff: %v", path, err)
+		}
+		if _, err := OptionalDuration(a.MaxBackoff); err != nil {
+			return fmt.Errorf("%s.max_backoff: %v", path, err)
+		}
+	}
+
+	return nil
+}
+
+// --------------------
+// Gateway validation
+// --------------------
+
+func validateGatewayRoutes(gates []IngressGate, memories map[memIdentity]string) error {
+	for i, g := range gates {
+		if len(g.Gateway) == 0 {
+			continue
+		}
+
+		if g.IsIngest() || g.IsUDPIngest() || !g.ModbusEnabled() {
+			return fmt.Errorf("listeners[%d] (%s).gateway: requires a Modbus listener", i, g.ID)
+		}
+
+		port, err := parseListenPort(g.Listen)
+		if err != nil {
+			return fmt.Errorf("listeners[%d] (%s): invalid listen %q: %w", i, g.ID, g.Listen, err)
+		}
+
+		seen := make(map[uint16]struct{}, len(g.Gateway))
+
+		for ri, r := range g.Gateway {
+			path := fmt.Sprintf("listeners[%d] (%s).gateway[%d]", i, g.ID, ri)
+
+			if r.UnitID > 0xFF {
+				return fmt.Errorf("%s.unit_id must be <= 255", path)
+			}
+			if _, ok := seen[r.UnitID]; ok {
+				return fmt.Errorf("%s: duplicate unit_id %d", path, r.UnitID)
+			}
+			seen[r.UnitID] = struct{}{}
+
+			if prev, ok := memories[memIdentity{port: port, unit: r.UnitID}]; ok {
+				return fmt.Errorf("%s: unit_id %d is a local memory (%s)", path, r.UnitID, prev)
+			}
+			if _, _, err := net.SplitHostPort(r.Address); err != nil {
+				return fmt.Errorf("%s.address: expected host:port, got %q", path, r.Address)
+			}
+			if r.RemoteUnitID != nil && *r.RemoteUnitID > 0xFF {
+				return fmt.Errorf("%s.remote_unit_id must be <= 255", path)
+			}
+			if _, err := OptionalDuration(r.Timeout); err != nil {
+				return fmt.Errorf("%s.timeout: %v", path, err)
+			}
+			if err := validatePolicy(path, r.Policy); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// --------------------
+// Poller validation
+// --------------------
+
+func validatePollers(pollers []PollerDevice, defs map[memIdentity]MemoryDefinition) error {
+	seen := make(map[string]struct{})
+
+	for i, p := range pollers {
+		if p.ID == "" {
+			return fmt.Errorf("pollers[%d]: id is required", i)
+		}
+		if _, ok := seen[p.ID]; ok {
+			return fmt.Errorf("pollers[%d]: duplicate id %q", i, p.ID)
+		}
+		seen[p.ID] = struct{}{}
+
+		path := fmt.Sprintf("pollers[%d] (%s)", i, p.ID)
+
+		if _, _, err := net.SplitHostPort(p.Address); err != nil {
+			return fmt.Errorf("%s.address: expected host:port, got %q", path, p.Address)
+		}
+		if p.UnitID > 0xFF {
+			return fmt.Errorf("%s.unit_id must be <= 255", path)
+		}
+		if _, err := OptionalDuration(p.Interval); err != nil {
+			return fmt.Errorf("%s.interval: %v", path, err)
+		}
+		if _, err := OptionalDuration(p.Timeout); err != nil {
+			return fmt.Errorf("%s.timeout: %v", path, err)
+		}
+		if p.Retries < 0 {
+			return fmt.Errorf("%s.retries: must be >= 0", path)
+		}
+
+		def, ok := defs[memIdentity{port: p.Target.Port, unit: p.Target.UnitID}]
+		if !ok {
+			return fmt.Errorf("%s.target: no memory configured for (port=%d unit=%d)", path, p.Target.Port, p.Target.UnitID)
+		}
+
+		if len(p.Reads) == 0 {
+			return fmt.Errorf("%s.reads: at least one read is required", path)
+		}
+		for ri, r := range p.Reads {
+			rpath := fmt.Sprintf("%s.reads[%d]", path, ri)
+
+			var area memorycore.Area
+			var max uint16
+			switch r.Function {
+			case 1:
+				area, max = memorycore.AreaCoils, 2000
+			case 2:
+				area, max = memorycore.AreaDiscreteInputs, 2000
+			case 3:
+				area, max = memorycore.AreaHoldingRegs, 125
+			case 4:
+				area, max = memorycore.AreaInputRegs, 125
+			default:
+				return fmt.Errorf("%s.function: must be 1, 2, 3 or 4, got %d", rpath, r.Function)
+			}
+			if r.Count == 0 || r.Count > max {
+				return fmt.Errorf("%s.count: must be 1..%d, got %d", rpath, max, r.Count)
+			}
+
+			local := r.Address
+			if r.LocalAddress != nil {
+				local = *r.LocalAddress
+			}
+			if err := checkAreaRange(def, area, local, r.Count); err != nil {
+				return fmt.Errorf("%s: %w", rpath, err)
+			}
+		}
+
+		if p.Status != nil {
+			area, err := memorycore.ParseArea(p.Status.Area)
+			if err != nil || (area != memorycore.AreaHoldingRegs && area != memorycore.AreaInputRegs) {
+				return fmt.Errorf("%s.status.area: must be 'holding_registers' or 'input_registers', got %q", path, p.Status.Area)
+			}
+			p.Status.ResolvedArea = area
+			if err := checkAreaRange(def, area, p.Status.Address, 3); err != nil {
+				return fmt.Errorf("%s.status: %w", path, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// --------------------
+// Egress validation
+// --------------------
+
+func validateEgress(subs []EgressSubscription, defs map[memIdentity]MemoryDefinition) error {
+	seen := make(map[string]struct{})
+
+	for i, e := range subs {
+		if e.ID == "" {
+			return fmt.Errorf("egress[%d]: id is required", i)
+		}
+		if _, ok := seen[e.ID]; ok {
+			return fmt.Errorf("egress[%d]: duplicate id %q", i, e.ID)
+		}
+		seen[e.ID] = struct{}{}
+
+		path := fmt.Sprintf("egress[%d] (%s)", i, e.ID)
+
+		def, ok := defs[memIdentity{port: e.Source.Port, unit: e.Source.UnitID}]
+		if !ok {
+			return fmt.Errorf("%s.source: no memory configured for (port=%d unit=%d)", path, e.Source.Port, e.Source.UnitID)
+		}
+
+		area, err := memorycore.ParseArea(e.Area)
+		if err != nil || (area != memorycore.AreaCoils && area != memorycore.AreaHoldingRegs) {
+			return fmt.Errorf("%s.area: must be 'coils' or 'holding_registers', got %q", path, e.Area)
+		}
+		subs[i].ResolvedArea = area
+		if e.Count == 0 {
+			return fmt.Errorf("%s.count: must be > 0", path)
+		}
+		if err := checkAreaRange(def, area, e.Start, e.Count); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		if e.QueueSize < 0 {
+			return fmt.Errorf("%s.queue_size: must be >= 0", path)
+		}
+		if e.Retries != nil && *e.Retries < 0 {
+			return fmt.Errorf("%s.retries: must be >= 0", path)
+		}
+
+		if err := validateEgressSink(path+".sink", e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateEgressSink(path string, e EgressSubscription) error {
+	s := e.Sink
+
+	if _, err := OptionalDuration(s.Timeout); err != nil {
+		return fmt.Errorf("%s.timeout: %v", path, err)
+	}
+
+	switch s.Type {
+	case "tcp", "modbus":
+		if _, _, err := net.SplitHostPort(s.Address); err != nil {
+			return fmt.Errorf("%s.address: expected host:port, got %q", path, s.Address)
+		}
+		if s.URL != "" {
+			return fmt.Errorf("%s.url: only valid for type 'webhook'", path)
+		}
+		if s.RemoteAddress != nil && uint32(*s.RemoteAddress)+uint32(e.Count) > 0x10000 {
+			return fmt.Errorf("%s.remote_address: range %d+%d exceeds 65536", path, *s.RemoteAddress, e.Count)
+		}
+		if s.Type == "modbus" {
+			unit := e.Source.UnitID
+			if s.UnitID != nil {
+				unit = *s.UnitID
+			}
+			if unit > 0xFF {
+				return fmt.Errorf("%s.unit_id must be <= 255", path)
+			}
+		}
+
+	case "webhook":
+		u, err := url.Parse(s.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%s.url: expected http(s) URL, got %q", path, s.URL)
+		}
+		if s.Address != "" || s.UnitID != nil || s.RemoteAddress != nil {
+			return fmt.Errorf("%s: address, unit_id and remote_address are not valid for type 'webhook'", path)
+		}
+
+	default:
+		return fmt.Errorf("%s.type: must be 'tcp', 'webhook' or 'modbus', got %q", path, s.Type)
+	}
+
+	return nil
+}
+
+// memoryDefinitions indexes every memory definition by identity.
+// Call after validateAllMemories (listen ports are known to parse).
+func memoryDefinitions(cfg *Config) map[memIdentity]MemoryDefinition {
+	out := make(map[memIdentity]MemoryDefinition)
+
+	for _, def := range cfg.Memory.Memories {
+		out[memIdentity{port: def.Port, unit: def.UnitID}] = def
+	}
+	for _, l := range cfg.Ingress {
+		if len(l.Memory) == 0 {
+			continue
+		}
+		port, err := parseListenPort(l.Listen)
+		if err != nil {
+			continue
+		}
+		for _, def := range l.Memory {
+			out[memIdentity{port: port, unit: def.UnitID}] = def
+		}
+	}
+
+	return out
+}
+
+// checkAreaRange reports whether [address, address+count) lies within
+// the allocated area of a memory definition.
+func checkAreaRange(def MemoryDefinition, area memorycore.Area, address, count uint16) error {
+	var a Area
+	switch area {
+	case memorycore.AreaCoils:
+		a = def.Coils
+	case memorycore.AreaDiscreteInputs:
+		a = def.DiscreteInputs
+	case memorycore.AreaHoldingRegs:
+		a = def.HoldingRegs
+	case memorycore.AreaInputRegs:
+		a = def.InputRegs
+	case memorycore.AreaFileRecords:
+		a = Area{Start: 0, Count: def.FileRecords.Files * def.FileRecords.Records}
+	}
+
+	if a.Count == 0 {
+		return fmt.Errorf("%s not allocated in target memory", area)
+	}
+
+	end := uint32(a.Start) + uint32(a.Count)
+	if uint32(address) < uint32(a.Start) || uint32(address)+uint32(count) > end {
+		return fmt.Errorf("%s %d+%d out of bounds [%d..%d)", area, address, count, a.Start, end)
+	}
+	return nil
+}
+
+// --------------------
+// Ingest listeners
+// --------------------
+
+// validateIngestListener checks the structure of a kind: ingest listener.
+// Target existence is checked later, once all memories are known.
+func validateIngestListener(i int, g IngressGate) error {
+	if len(g.Memory) > 0 {
+		return fmt.Errorf("listeners[%d] (%s).memory: not allowed on kind: ingest (use targets)", i, g.ID)
+	}
+	if len(g.Targets) == 0 {
+		return fmt.Errorf("listeners[%d] (%s).targets: required for kind: ingest", i, g.ID)
+	}
+	if g.Protocols != nil {
+		return fmt.Errorf("listeners[%d] (%s).protocols: not allowed on kind: ingest (raw_frame only)", i, g.ID)
+	}
+	if p := strings.ToLower(strings.TrimSpace(g.Protocol)); p != "" && p != "raw_frame" {
+		return fmt.Errorf("listeners[%d] (%s).protocol: kind: ingest serves raw_frame only, got %q", i, g.ID, g.Protocol)
+	}
+	if g.IngestAuth != nil {
+		return fmt.Errorf("listeners[%d] (%s).ingest_auth: not allowed on kind: ingest (declare it on the target memory)", i, g.ID)
+	}
+	if g.Reply {
+		return fmt.Errorf("listeners[%d] (%s).reply: only allowed on kind: udp_ingest", i, g.ID)
+	}
+	return nil
+}
+
+// validateUDPIngestListener checks the structure of a kind: udp_ingest listener.
+// The memories it writes are those of its listen port (checked later).
+func validateUDPIngestListener(i int, g IngressGate) error {
+	if len(g.Memory) > 0 {
+		return fmt.Errorf("listeners[%d] (%s).memory: not allowed on kind: udp_ingest (define it on the TCP listener of the same port)", i, g.ID)
+	}
+	if len(g.Targets) > 0 {
+		return fmt.Errorf("listeners[%d] (%s).targets: only allowed on kind: ingest", i, g.ID)
+	}
+	if g.Protocols != nil || strings.TrimSpace(g.Protocol) != "" {
+		return fmt.Errorf("listeners[%d] (%s): protocols/protocol not allowed on kind: udp_ingest (raw_ingest only)", i, g.ID)
+	}
+	if strings.TrimSpace(g.ClassifyTimeout) != "" || g.MaxPacketBytes != 0 {
+		return fmt.Errorf("listeners[%d] (%s): classify_timeout/max_packet_bytes not allowed on kind: udp_ingest", i, g.ID)
+	}
+	if g.IngestAuth != nil {
+		return fmt.Errorf("listeners[%d] (%s).ingest_auth: not allowed on kind: udp_ingest (declare it on the target memory)", i, g.ID)
+	}
+	if _, err := parseListenPort(g.Listen); err != nil {
+		return fmt.Errorf("listeners[%d] (%s): invalid listen %q: %w", i, g.ID, g.Listen, err)
+	}
+	return nil
+}
+
+// validateIngestTargets ensures every ingest target is a configured memory
+// and is listed once, and that udp_ingest ports have memory.
+func validateIngestTargets(gates []IngressGate, memories map[memIdentity]string) error {
+	for i, g := range gates {
+		if g.IsUDPIngest() {
+			if err := validateUDPIngestPort(i, g, memories); err != nil {
+				return err
+			}
+			continue
+		}
+
+		seen := make(map[memIdentity]struct{}, len(g.Targets))
+
+		for ti, t := range g.Targets {
+			id := memIdentity{port: t.Port, unit: t.UnitID}
+			path := fmt.Sprintf("listeners[%d] (%s).targets[%d]", i, g.ID, ti)
+
+			if _, ok := memories[id]; !ok {
+				return fmt.Errorf("%s: no memory configured for (port=%d unit=%d)", path, t.Port, t.UnitID)
+			}
+			if _, ok := seen[id]; ok {
+				return fmt.Errorf("%s: duplicate target (port=%d unit=%d)", path, t.Port, t.UnitID)
+			}
+			seen[id] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// validateUDPIngestPort ensures a udp_ingest listen port has memory to write.
+func validateUDPIngestPort(i int, g IngressGate, memories map[memIdentity]string) error {
+	port, err := parseListenPort(g.Listen)
+	if err != nil {
+		return fmt.Errorf("listeners[%d] (%s): invalid listen %q: %w", i, g.ID, g.Listen, err)
+	}
+	for id := range memories {
+		if id.port == port {
+			return nil
+		}
+	}
+	return fmt.Errorf("listeners[%d] (%s): no memory configured for port %d", i, g.ID, port)
+}
+
 func validateLegacyMemoryDef(memKey string, def MemoryDefinition) error {
 	if def.Port == 0 {
 		return fmt.Errorf("memory[%s]: port must be > 0", memKey)
@@ -146,6 +788,15 @@ func validateLegacyMemoryDef(memKey string, def MemoryDefinition) error {
 	if err := validatePolicy(memKey, def.Policy); err != nil {
 		return err
 	}
+	if err := validateIngestPolicy(memKey, def.IngestPolicy); err != nil {
+		return err
+	}
+	if _, err := loadIngestKeys(def.IngestAuth); err != nil {
+		return fmt.Errorf("%s.%w", memKey, err)
+	}
+	if _, err := resolveDeviceIdentity(def); err != nil {
+		return fmt.Errorf("%s.%w", memKey, err)
+	}
 
 	return nil
 }
@@ -169,6 +820,15 @@ func validateNestedMemoryDef(li, mi int, listenerID string, port uint16, def Mem
 	if err := validatePolicy(memKey, def.Policy); err != nil {
 		return err
 	}
+	if err := validateIngestPolicy(memKey, def.IngestPolicy); err != nil {
+		return err
+	}
+	if _, err := loadIngestKeys(def.IngestAuth); err != nil {
+		return fmt.Errorf("%s.%w", memKey, err)
+	}
+	if _, err := resolveDeviceIdentity(def); err != nil {
+		return fmt.Errorf("%s.%w", memKey, err)
+	}
 
 	return nil
 }
@@ -186,7 +846,25 @@ func validateAreas(memKey string, def MemoryDefinition) error {
 	if err := validateArea(memKey, "input_registers", def.InputRegs); err != nil {
 		return err
 	}
-	return nil
+	return validateFileRecords(memKey, def.FileRecords)
+}
+
+func validateFileRecords(memKey string, f FileRecordsArea) error {
+	if f.Files == 0 && f.Records == 0 {
+		return nil
+	}
+
+	l := memorycore.FileLayout{Files: f.Files, Records: f.Records}
+	switch err := l.Validate(); err {
+	case nil:
+		return nil
+	case memorycore.ErrSizeZero:
+		return fmt.Errorf("%s.file_records: files and records must both be > 0", memKey)
+	case memorycore.ErrOutOfBounds:
+		return fmt.Errorf("%s.file_records.records: must be <= %d", memKey, memorycore.MaxFileRecords)
+	default:
+		return fmt.Errorf("%s.file_records: files(%d)*records(%d) exceeds 65535 words", memKey, f.Files, f.Records)
+	}
 }
 
 func validateArea(memKey, name string, a Area) error {
@@ -210,31 +888,9 @@ func validateArea(memKey, name string, a Area) error {
 // --------------------
 
 func validateStateSealing(memKey string, def MemoryDefinition) error {
-	if def.StateSealing == nil {
-		return nil
-	}
-
-	area := strings.ToLower(strings.TrimSpace(def.StateSealing.Area))
-	if area != "coil" {
-		return fmt.Errorf("%s.state_sealing.area must be 'coil'", memKey)
-	}
-
-	if def.Coils.Count == 0 {
-		return fmt.Errorf("%s.state_sealing requires coils to be allocated", memKey)
-	}
-
-	start := def.Coils.Start
-	count := def.Coils.Count
-	addr := def.StateSealing.Address
-
-	endExclusive := uint32(start) + uint32(count)
-	if uint32(addr) < uint32(start) || uint32(addr) >= endExclusive {
-		return fmt.Errorf(
-			"%s.state_sealing.address (%d) out of bounds for coils [%d..%d)",
-			memKey, addr, start, uint16(endExclusive),
-		)
+	if _, err := resolveStateSealing(def); err != nil {
+		return fmt.Errorf("%s.%w", memKey, err)
 	}
-
 	return nil
 }
 
@@ -273,6 +929,55 @@ func validatePolicy(memKey string, p *MemoryPolicyConfig) error {
 	return nil
 }
 
+func validateIngestPolicy(memKey string, p *IngestPolicyConfig) error {
+	if p == nil {
+		return nil
+	}
+
+	for i, r := range p.Rules {
+		rulePath := fmt.Sprintf("%s.ingest_policy.rules[%d]", memKey, i)
+
+		if strings.TrimSpace(r.ID) == "" {
+			return fmt.Errorf("%s: id is required", rulePath)
+		}
+
+		if err := validateCIDRList(rulePath+".source_ip", r.SourceIP); err != nil {
+			return err
+		}
+
+		if len(r.Allow) == 0 {
+			return fmt.Errorf("%s.allow: at least one range is required", rulePath)
+		}
+
+		for j, a := range r.Allow {
+			if _, err := memorycore.ParseArea(a.Area); err != nil {
+				return fmt.Errorf("%s.allow[%d]: %v", rulePath, j, err)
+			}
+			if a.Count == 0 {
+				return fmt.Errorf("%s.allow[%d]: count must be > 0", rulePath, j)
+			}
+			if err := validateArea(rulePath, fmt.Sprintf("allow[%d]", j), Area{Start: a.Start, Count: a.Count}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateCIDRList(path string, items []string) error {
+	for j, s := range items {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if _, err := parseIPOrCIDR(s); err != nil {
+			return fmt.Errorf("%s[%d]: invalid ip/cidr %q: %v", path, j, s, err)
+		}
+	}
+	return nil
+}
+
 func parseIPOrCIDR(s string) (any, error) {
 	if pfx, err := netip.ParsePrefix(s); err == nil {
 		return pfx, nil
@@ -0,0 +1,37 @@
+// internal/egress/dispatcher.go
+package egress
+
+import (
+	"MMA2.0/internal/memorycore"
+	"MMA2.0/internal/transport/modbus"
+)
+
+// Dispatcher fans Modbus writes out to the subscriptions of their memory.
+// Subscriptions are added before the dispatcher is attached to a server;
+// the table is read-only afterwards.
+type Dispatcher struct {
+	subs map[memorycore.MemoryID][]*Subscription
+}
+
+// NewDispatcher creates an empty dispatcher.
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{subs: make(map[memorycore.MemoryID][]*Subscription)}
+}
+
+// Add registers a subscription.
+func (d *Dispatcher) Add(s *Subscription) {
+	d.subs[s.opts.Memory] = append(d.subs[s.opts.Memory], s)
+}
+
+// ObserveWrite implements modbus.WriteObserver. It never blocks.
+func (d *Dispatcher) ObserveWrite(w modbus.AppliedWrite) {
+	for _, s := range d.subs[w.MemoryID] {
+		ev, ok := s.match(w.Area, w.Address, w.Count, w.Src)
+		if !ok {
+			continue
+		}
+		ev.SourceIP = w.SourceIP
+		ev.FunctionCode = w.FunctionCode
+		s.enqueue(ev)
+	}
+}
@@ -0,0 +1,282 @@
+// internal/egress/egress.go
+package egress
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/netip"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"MMA2.0/internal/memorycore"
+)
+
+// Egress is write-back: when a Modbus client writes into a watched range
+// of a memory, the written values are pushed to a downstream sink.
+//
+// Delivery is bounded:
+//   - every subscription owns a fixed-size queue; when it is full the new
+//     event is dropped (never blocks the Modbus connection)
+//   - one worker per subscription delivers events in order, with a fixed
+//     number of retries per event
+//   - a sink rejection (the receiver answered and refused) is not retried
+//
+// Delivery is at-most-once per attempt sequence: a retried event may be
+// received twice if the receiver applied it but the reply was lost.
+
+// Defaults.
+const (
+	DefaultQueueSize = 256
+	DefaultRetries   = 2
+	DefaultTimeout   = 5 * time.Second
+
+	retryBackoff    = 200 * time.Millisecond
+	maxRetryBackoff = 5 * time.Second
+
+	// Counters are logged at this interval while they change.
+	statsInterval = time.Minute
+)
+
+// Event is one write clipped to a subscription range.
+type Event struct {
+	Subscription string
+	Seq          uint64 // per subscription, starts at 1; gaps are drops
+
+	Memory       memorycore.MemoryID
+	SourceIP     netip.Addr
+	FunctionCode uint8
+
+	Area    memorycore.Area
+	Address uint16 // local address
+	Count   uint16
+
+	// RemoteAddress is Address mapped into the sink's address space.
+	RemoteAddress uint16
+
+	// Src holds the values in memorycore encoding:
+	// packed bits (LSB-first) or big-endian registers.
+	Src []byte
+}
+
+// Sink delivers events to one downstream system.
+// Deliver is called from a single goroutine; it must honor its own timeout.
+type Sink interface {
+	Deliver(ev Event) error
+	Close() error
+}
+
+// RejectedError reports that the receiver answered and refused an event.
+// Rejected events are not retried.
+type RejectedError struct {
+	Reason string
+}
+
+func (e *RejectedError) Error() string { return "rejected: " + e.Reason }
+
+// Options configures one subscription.
+type Options struct {
+	ID     string
+	Memory memorycore.MemoryID
+
+	Area  memorycore.Area // coils | holding registers
+	Start uint16
+	Count uint16
+
+	// RemoteAddress is the sink address of Start.
+	RemoteAddress uint16
+
+	QueueSize int
+	Retries   int // extra attempts per event
+}
+
+// Stats are the delivery counters of one subscription.
+type Stats struct {
+	Enqueued  uint64
+	Delivered uint64
+	Retried   uint64
+	Rejected  uint64
+	Failed    uint64 // retries exhausted
+	Dropped   uint64 // queue full
+	Pending   int
+}
+
+func (st Stats) String() string {
+	return fmt.Sprintf("enqueued=%d delivered=%d retried=%d rejected=%d failed=%d dropped=%d pending=%d",
+		st.Enqueued, st.Delivered, st.Retried, st.Rejected, st.Failed, st.Dropped, st.Pending)
+}
+
+// Subscription watches one range and feeds one sink.
+type Subscription struct {
+	opts Options
+	sink Sink
+
+	queue chan Event
+	seq   atomic.Uint64
+
+	done     chan struct{}
+	stopOnce sync.Once
+
+	enqueued  atomic.Uint64
+	delivered atomic.Uint64
+	retried   atomic.Uint64
+	rejected  atomic.Uint64
+	failed    atomic.Uint64
+	dropped   atomic.Uint64
+	overflow  atomic.Bool
+}
+
+// NewSubscription creates a subscription. A zero QueueSize selects the default.
+func NewSubscription(opts Options, sink Sink) (*Subscription, error) {
+	if opts.Area != memorycore.AreaCoils && opts.Area != memorycore.AreaHoldingRegs {
+		return nil, fmt.Errorf("egress %s: area %s is not writable by Modbus clients", opts.ID, opts.Area)
+	}
+	if opts.Count == 0 || uint32(opts.Start)+uint32(opts.Count) > 0x10000 {
+		return nil, fmt.Errorf("egress %s: invalid range %d+%d", opts.ID, opts.Start, opts.Count)
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultQueueSize
+	}
+	if opts.Retries < 0 {
+		opts.Retries = 0
+	}
+	return &Subscription{
+		opts:  opts,
+		sink:  sink,
+		queue: make(chan Event, opts.QueueSize),
+		done:  make(chan struct{}),
+	}, nil
+}
+
+// Stats returns the subscription counters.
+func (s *Subscription) Stats() Stats {
+	return Stats{
+		Enqueued:  s.enqueued.Load(),
+		Delivered: s.delivered.Load(),
+		Retried:   s.retried.Load(),
+		Rejected:  s.rejected.Load(),
+		Failed:    s.failed.Load(),
+		Dropped:   s.dropped.Load(),
+		Pending:   len(s.queue),
+	}
+}
+
+// Stop ends Run. Pending events are discarded.
+func (s *Subscription) Stop() {
+	s.stopOnce.Do(func() { close(s.done) })
+}
+
+// Run delivers queued events until Stop is called.
+func (s *Subscription) Run() {
+	defer s.sink.Close()
+
+	t := time.NewTicker(statsInterval)
+	defer t.Stop()
+
+	var last Stats
+	for {
+		select {
+		case <-s.done:
+			return
+		case ev := <-s.queue:
+			s.deliver(ev)
+		case <-t.C:
+			st := s.Stats()
+			if st != last {
+				log.Printf("egress %s: %s", s.opts.ID, st)
+				last = st
+			}
+		}
+	}
+}
+
+// enqueue never blocks; a full queue drops the event.
+func (s *Subscription) enqueue(ev Event) {
+	ev.Subscription = s.opts.ID
+	ev.Seq = s.seq.Add(1)
+
+	select {
+	case s.queue <- ev:
+		s.enqueued.Add(1)
+		s.overflow.Store(false)
+	default:
+		n := s.dropped.Add(1)
+		if !s.overflow.Swap(true) {
+			log.Printf("egress %s: queue full (%d), dropping events (dropped=%d)", s.opts.ID, s.opts.QueueSize, n)
+		}
+	}
+}
+
+func (s *Subscription) deliver(ev Event) {
+	backoff := retryBackoff
+
+	for attempt := 0; ; attempt++ {
+		err := s.sink.Deliver(ev)
+		if err == nil {
+			s.delivered.Add(1)
+			return
+		}
+
+		var rej *RejectedError
+		if errors.As(err, &rej) {
+			n := s.rejected.Add(1)
+			log.Printf("egress %s: seq %d %s@%d+%d %v (rejected=%d)",
+				s.opts.ID, ev.Seq, ev.Area, ev.Address, ev.Count, err, n)
+			return
+		}
+
+		if attempt >= s.opts.Retries {
+			n := s.failed.Add(1)
+			log.Printf("egress %s: seq %d %s@%d+%d failed after %d attempts: %v (failed=%d)",
+				s.opts.ID, ev.Seq, ev.Area, ev.Address, ev.Count, attempt+1, err, n)
+			return
+		}
+
+		s.retried.Add(1)
+		select {
+		case <-s.done:
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+}
+
+// match clips a write to the subscription range.
+func (s *Subscription) match(area memorycore.Area, address, count uint16, src []byte) (Event, bool) {
+	if area != s.opts.Area {
+		return Event{}, false
+	}
+
+	lo := max(uint32(address), uint32(s.opts.Start))
+	hi := min(uint32(address)+uint32(count), uint32(s.opts.Start)+uint32(s.opts.Count))
+	if lo >= hi {
+		return Event{}, false
+	}
+
+	off := uint16(lo - uint32(address))
+	n := uint16(hi - lo)
+
+	var out []byte
+	if area.IsBitArea() {
+		out = make([]byte, (int(n)+7)/8)
+		for i := uint16(0); i < n; i++ {
+			j := off + i
+			if src[j/8]&(1<<(j%8)) != 0 {
+				out[i/8] |= 1 << (i % 8)
+			}
+		}
+	} else {
+		out = append([]byte(nil), src[int(off)*2:int(off+n)*2]...)
+	}
+
+	return Event{
+		Memory:        s.opts.Memory,
+		Area:          area,
+		Address:       uint16(lo),
+		Count:         n,
+		RemoteAddress: s.opts.RemoteAddress + uint16(lo) - s.opts.Start,
+		Src:           out,
+	}, true
+}
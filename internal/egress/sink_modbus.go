@@ -0,0 +1,65 @@
+// internal/egress/sink_modbus.go
+package egress
+
+import (
+	"errors"
+	"time"
+
+	"MMA2.0/internal/memorycore"
+	"MMA2.0/internal/transport/modbus"
+)
+
+// ModbusSink writes events to a downstream Modbus TCP device:
+// coils with FC15, holding registers with FC16.
+// The connection is redialed after any error other than an exception.
+type ModbusSink struct {
+	Address string
+	UnitID  uint8
+	Timeout time.Duration
+
+	client *modbus.Client
+}
+
+// Deliver performs one write request.
+func (s *ModbusSink) Deliver(ev Event) error {
+	if s.client == nil {
+		timeout := s.Timeout
+		if timeout <= 0 {
+			timeout = DefaultTimeout
+		}
+		c, err := modbus.Dial(s.Address, timeout)
+		if err != nil {
+			return err
+		}
+		s.client = c
+	}
+
+	var err error
+	switch ev.Area {
+	case memorycore.AreaCoils:
+		err = s.client.WriteBits(s.UnitID, ev.RemoteAddress, ev.Count, ev.Src)
+	case memorycore.AreaHoldingRegs:
+		err = s.client.WriteRegs(s.UnitID, ev.RemoteAddress, ev.Count, ev.Src)
+	default:
+		return &RejectedError{Reason: "area " + ev.Area.String()}
+	}
+
+	var exc *modbus.ExceptionError
+	if errors.As(err, &exc) {
+		return &RejectedError{Reason: exc.Error()}
+	}
+	if err != nil {
+		s.Close()
+	}
+	return err
+}
+
+// Close drops the connection (the next Deliver redials).
+func (s *ModbusSink) Close() error {
+	if s.client == nil {
+		return nil
+	}
+	err := s.client.Close()
+	s.client = nil
+	return err
+}
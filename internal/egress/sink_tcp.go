@@ -0,0 +1,84 @@
+// internal/egress/sink_tcp.go
+package egress
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"time"
+
+	"MMA2.0/internal/transport/rawingest"
+)
+
+// TCPSink streams raw ingest v2 frames to a raw_ingest listener and waits
+// for the 8-byte reply of each frame. The connection is redialed after
+// any I/O error.
+type TCPSink struct {
+	Address string
+	UnitID  uint16
+	Timeout time.Duration
+
+	conn net.Conn
+	r    *bufio.Reader
+	seq  uint32
+	buf  []byte
+}
+
+// Deliver sends one frame and checks its reply.
+func (s *TCPSink) Deliver(ev Event) error {
+	frame, err := rawingest.AppendV2(s.buf[:0], s.seq+1, s.UnitID, ev.Area, ev.RemoteAddress, ev.Count, ev.Src)
+	if err != nil {
+		return &RejectedError{Reason: err.Error()}
+	}
+	s.buf = frame
+
+	if s.conn == nil {
+		conn, err := net.DialTimeout("tcp", s.Address, s.timeout())
+		if err != nil {
+			return err
+		}
+		s.conn, s.r = conn, bufio.NewReader(conn)
+	}
+
+	s.seq++
+	status, seq, err := s.exchange(frame)
+	if err != nil {
+		s.Close()
+		return err
+	}
+	if seq != s.seq {
+		s.Close()
+		return fmt.Errorf("reply sequence %d, want %d", seq, s.seq)
+	}
+	if status != rawingest.StatusOK {
+		return &RejectedError{Reason: fmt.Sprintf("status 0x%02X", status)}
+	}
+	return nil
+}
+
+func (s *TCPSink) exchange(frame []byte) (byte, uint32, error) {
+	if err := s.conn.SetDeadline(time.Now().Add(s.timeout())); err != nil {
+		return 0, 0, err
+	}
+	if _, err := s.conn.Write(frame); err != nil {
+		return 0, 0, err
+	}
+	return rawingest.ReadReplyV2(s.r)
+}
+
+// Close drops the connection (the next Deliver redials).
+func (s *TCPSink) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn, s.r = nil, nil
+	return err
+}
+
+func (s *TCPSink) timeout() time.Duration {
+	if s.Timeout <= 0 {
+		return DefaultTimeout
+	}
+	return s.Timeout
+}
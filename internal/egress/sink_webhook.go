@@ -0,0 +1,107 @@
+// internal/egress/sink_webhook.go
+package egress
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"MMA2.0/internal/ingest"
+)
+
+// WebhookSink POSTs each event as a JSON write request (the REST ingest
+// schema, local coordinates), so the receiver may be another MMA.
+// Metadata travels in headers:
+//
+//	X-Egress-Subscription, X-Egress-Seq, X-Egress-Source, X-Egress-Function
+//
+// 2xx is success; other 4xx are rejections; everything else is retried.
+type WebhookSink struct {
+	URL     string
+	Timeout time.Duration
+
+	client *http.Client
+}
+
+// Deliver posts one event.
+func (s *WebhookSink) Deliver(ev Event) error {
+	if s.client == nil {
+		timeout := s.Timeout
+		if timeout <= 0 {
+			timeout = DefaultTimeout
+		}
+		s.client = &http.Client{Timeout: timeout}
+	}
+
+	body, err := json.Marshal(webhookBody(ev))
+	if err != nil {
+		return &RejectedError{Reason: err.Error()}
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return &RejectedError{Reason: err.Error()}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Egress-Subscription", ev.Subscription)
+	req.Header.Set("X-Egress-Seq", strconv.FormatUint(ev.Seq, 10))
+	req.Header.Set("X-Egress-Function", strconv.Itoa(int(ev.FunctionCode)))
+	if ev.SourceIP.IsValid() {
+		req.Header.Set("X-Egress-Source", ev.SourceIP.String())
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests:
+		return &RejectedError{Reason: resp.Status}
+	default:
+		return fmt.Errorf("http %s", resp.Status)
+	}
+}
+
+// Close releases idle connections.
+func (s *WebhookSink) Close() error {
+	if s.client != nil {
+		s.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+func webhookBody(ev Event) ingest.WriteRequest {
+	port, unit, addr := ev.Memory.Port, ev.Memory.UnitID, ev.Address
+
+	values := make([]json.RawMessage, 0, ev.Count)
+	for i := 0; i < int(ev.Count); i++ {
+		var v string
+		if ev.Area.IsBitArea() {
+			v = strconv.FormatBool(ev.Src[i/8]&(1<<(i%8)) != 0)
+		} else {
+			v = strconv.Itoa(int(binary.BigEndian.Uint16(ev.Src[i*2:])))
+		}
+		values = append(values, json.RawMessage(v))
+	}
+
+	return ingest.WriteRequest{
+		Port:   &port,
+		UnitID: &unit,
+		WriteSpec: ingest.WriteSpec{
+			Area:    ev.Area.String(),
+			Address: &addr,
+			Values:  values,
+		},
+	}
+}
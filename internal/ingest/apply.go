@@ -0,0 +1,83 @@
+// internal/ingest/apply.go
+package ingest
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"MMA2.0/internal/authority"
+	"MMA2.0/internal/memorycore"
+)
+
+// Shared write path for every write-only ingest transport.
+// Transports own framing and authentication; this package owns the
+// order in which a decoded write reaches memory:
+//
+//  1. authentication requirement (authority ingest keys)
+//  2. resolve target memory (Port, UnitID)
+//  3. ingest policy per write (authority)
+//  4. atomic apply (memorycore.ApplyWrites)
+//  5. state sealing observation (authority.Sealing)
+
+var (
+	ErrNotAuthorized    = errors.New("not authorized")
+	ErrNotAuthenticated = errors.New("not authenticated")
+)
+
+// Source identifies the producer of an ingest write.
+type Source struct {
+	IP netip.Addr
+
+	// Authenticated is set by transports that verified the frame
+	// via authority.AuthenticateIngest.
+	Authenticated bool
+}
+
+// Apply authorizes and applies writes to one memory, all-or-nothing.
+// Memory is unchanged on any error.
+func Apply(
+	store *memorycore.Store,
+	auth *authority.Authority,
+	src Source,
+	mid memorycore.MemoryID,
+	writes []memorycore.Write,
+) error {
+	if !src.Authenticated && auth.IngestAuthRequired(mid) {
+		return ErrNotAuthenticated
+	}
+
+	mem, err := store.MustGet(mid)
+	if err != nil {
+		return err
+	}
+
+	for _, w := range writes {
+		decision := auth.Evaluate(authority.Request{
+			Kind:     authority.KindIngest,
+			MemoryID: mid,
+			SourceIP: src.IP,
+			Area:     w.Area,
+			Address:  w.Address,
+			Count:    w.Count,
+
+			Authenticated: src.Authenticated,
+		})
+		if !decision.Allowed {
+			return fmt.Errorf("%w: %s", ErrNotAuthorized, decision.Reason)
+		}
+	}
+
+	if _, err := mem.ApplyWrites(writes); err != nil {
+		return err
+	}
+
+	// State sealing: ingest is the only path that can unlock.
+	for _, w := range writes {
+		if w.Area.IsBitArea() {
+			auth.Sealing().ObserveIngestWrite(mid, w.Area, w.Address, w.Count, w.Src)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+// internal/ingest/codes.go
+package ingest
+
+import (
+	"errors"
+
+	"MMA2.0/internal/authority"
+	"MMA2.0/internal/memorycore"
+)
+
+// Error codes reported by text-based transports (stable, machine-readable).
+const (
+	CodeBadRequest       = "bad_request"
+	CodeUnknownMemory    = "unknown_memory"
+	CodeAreaNotDefined   = "area_not_defined"
+	CodeOutOfBounds      = "out_of_bounds"
+	CodeInvalidArea      = "invalid_area"
+	CodeNotAuthorized    = "not_authorized"
+	CodeNotAuthenticated = "not_authenticated"
+	CodeReplay           = "replay"
+	CodeRejected         = "rejected"
+)
+
+// CodeFor maps a request / authority / memorycore error to an error code.
+func CodeFor(err error) string {
+	switch {
+	case errors.Is(err, ErrBadRequest):
+		return CodeBadRequest
+	case errors.Is(err, memorycore.ErrInvalidArea):
+		return CodeInvalidArea
+	case errors.Is(err, memorycore.ErrUnknownMemoryID):
+		return CodeUnknownMemory
+	case errors.Is(err, memorycore.ErrAreaNotDefined):
+		return CodeAreaNotDefined
+	case errors.Is(err, memorycore.ErrOutOfBounds):
+		return CodeOutOfBounds
+	case errors.Is(err, ErrNotAuthorized):
+		return CodeNotAuthorized
+	case errors.Is(err, authority.ErrIngestReplay):
+		return CodeReplay
+	case errors.Is(err, ErrNotAuthenticated),
+		errors.Is(err, authority.ErrIngestAuthFailed),
+		errors.Is(err, authority.ErrIngestAuthNotConfigured):
+		return CodeNotAuthenticated
+	default:
+		return CodeRejected
+	}
+}
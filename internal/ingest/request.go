@@ -0,0 +1,172 @@
+// internal/ingest/request.go
+package ingest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"MMA2.0/internal/memorycore"
+)
+
+// ErrBadRequest reports a structurally invalid or ambiguous write request.
+var ErrBadRequest = errors.New("bad request")
+
+// maxRequestWrites caps the number of writes in one request.
+const maxRequestWrites = 256
+
+// WriteRequest is the JSON write schema shared by text-based transports.
+//
+// Targeting is always explicit. Exactly one of the two shapes is allowed:
+//
+//	single: {"port":502,"unit_id":1,"area":"holding_registers","address":0,"values":[1,2]}
+//	batch:  {"port":502,"unit_id":1,"writes":[{"area":...,"address":...,"values":[...]}, ...]}
+//
+// Bit areas take true/false (or 0/1); register areas take 0..65535.
+// A batch targets one memory and is applied all-or-nothing.
+type WriteRequest struct {
+	Port   *uint16 `json:"port"`
+	UnitID *uint16 `json:"unit_id"`
+
+	WriteSpec
+
+	Writes []WriteSpec `json:"writes,omitempty"`
+}
+
+// WriteSpec is one area write inside a WriteRequest.
+type WriteSpec struct {
+	Area    string            `json:"area"`
+	Address *uint16           `json:"address"`
+	Values  []json.RawMessage `json:"values"`
+}
+
+// DecodeWriteRequest parses one JSON write request strictly:
+// unknown fields and trailing data are rejected.
+func DecodeWriteRequest(data []byte) (*WriteRequest, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var req WriteRequest
+	if err := dec.Decode(&req); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadRequest, err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("%w: trailing data after request", ErrBadRequest)
+	}
+	return &req, nil
+}
+
+// Resolve validates the request and returns its target and writes.
+func (r *WriteRequest) Resolve() (memorycore.MemoryID, []memorycore.Write, error) {
+	if r.Port == nil || r.UnitID == nil {
+		return memorycore.MemoryID{}, nil, fmt.Errorf("%w: port and unit_id are required", ErrBadRequest)
+	}
+	mid := memorycore.MemoryID{Port: *r.Port, UnitID: *r.UnitID}
+
+	single := r.Area != "" || r.Address != nil || r.Values != nil
+
+	var specs []WriteSpec
+	switch {
+	case single && r.Writes != nil:
+		return mid, nil, fmt.Errorf("%w: ambiguous request (both single write fields and writes)", ErrBadRequest)
+	case single:
+		specs = []WriteSpec{r.WriteSpec}
+	case len(r.Writes) == 0:
+		return mid, nil, fmt.Errorf("%w: no writes", ErrBadRequest)
+	case len(r.Writes) > maxRequestWrites:
+		return mid, nil, fmt.Errorf("%w: too many writes (%d > %d)", ErrBadRequest, len(r.Writes), maxRequestWrites)
+	default:
+		specs = r.Writes
+	}
+
+	writes := make([]memorycore.Write, 0, len(specs))
+	for i, s := range specs {
+		w, err := s.resolve()
+		if err != nil {
+			if r.Writes != nil {
+				return mid, nil, fmt.Errorf("writes[%d]: %w", i, err)
+			}
+			return mid, nil, err
+		}
+		writes = append(writes, w)
+	}
+
+	return mid, writes, nil
+}
+
+func (s WriteSpec) resolve() (memorycore.Write, error) {
+	area, err := parseArea(s.Area)
+	if err != nil {
+		return memorycore.Write{}, err
+	}
+	if s.Address == nil {
+		return memorycore.Write{}, fmt.Errorf("%w: address is required", ErrBadRequest)
+	}
+	if len(s.Values) == 0 {
+		return memorycore.Write{}, fmt.Errorf("%w: values must be non-empty", ErrBadRequest)
+	}
+	if len(s.Values) > 0xFFFF {
+		return memorycore.Write{}, fmt.Errorf("%w: too many values", ErrBadRequest)
+	}
+
+	w := memorycore.Write{
+		Area:    area,
+		Address: *s.Address,
+		Count:   uint16(len(s.Values)),
+	}
+
+	if area.IsBitArea() {
+		w.Src = make([]byte, (len(s.Values)+7)/8)
+		for i, raw := range s.Values {
+			on, err := parseBit(raw)
+			if err != nil {
+				return memorycore.Write{}, fmt.Errorf("%w: values[%d]: %v", ErrBadRequest, i, err)
+			}
+			if on {
+				w.Src[i/8] |= 1 << (i % 8)
+			}
+		}
+		return w, nil
+	}
+
+	w.Src = make([]byte, len(s.Values)*2)
+	for i, raw := range s.Values {
+		v, err := parseReg(raw)
+		if err != nil {
+			return memorycore.Write{}, fmt.Errorf("%w: values[%d]: %v", ErrBadRequest, i, err)
+		}
+		binary.BigEndian.PutUint16(w.Src[i*2:i*2+2], v)
+	}
+	return w, nil
+}
+
+// parseArea maps a request payload area name to a memorycore area.
+func parseArea(s string) (memorycore.Area, error) {
+	if strings.TrimSpace(s) == "" {
+		return memorycore.AreaInvalid, fmt.Errorf("%w: area is required", ErrBadRequest)
+	}
+	return memorycore.ParseArea(s)
+}
+
+func parseBit(raw json.RawMessage) (bool, error) {
+	switch string(bytes.TrimSpace(raw)) {
+	case "true", "1":
+		return true, nil
+	case "false", "0":
+		return false, nil
+	default:
+		return false, fmt.Errorf("bit value must be true/false or 0/1, got %s", raw)
+	}
+}
+
+func parseReg(raw json.RawMessage) (uint16, error) {
+	n, err := strconv.ParseUint(string(bytes.TrimSpace(raw)), 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("register value must be an integer 0..65535, got %s", raw)
+	}
+	return uint16(n), nil
+}
@@ -3,7 +3,10 @@ package ingress
 
 import (
 	"bufio"
+	"encoding/binary"
+	"fmt"
 	"net"
+	"strings"
 )
 
 // Protocol identifies the ingress protocol.
@@ -13,24 +16,76 @@ const (
 	ProtocolUnknown Protocol = iota
 	ProtocolModbus
 	ProtocolRawIngest
+	ProtocolRawFrame
 )
 
+func (p Protocol) String() string {
+	switch p {
+	case ProtocolModbus:
+		return "modbus"
+	case ProtocolRawIngest:
+		return "raw_ingest"
+	case ProtocolRawFrame:
+		return "raw_frame"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseProtocol maps a configuration protocol name to a Protocol.
+func ParseProtocol(s string) (Protocol, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "modbus":
+		return ProtocolModbus, nil
+	case "raw_ingest":
+		return ProtocolRawIngest, nil
+	case "raw_frame":
+		return ProtocolRawFrame, nil
+	default:
+		return ProtocolUnknown, fmt.Errorf("unknown protocol %q", s)
+	}
+}
+
+// classifyPeekLen is the number of bytes needed for a deterministic decision.
+// Modbus MBAP (7+1), raw ingest (10) and raw frame (14) headers are all longer.
+const classifyPeekLen = 4
+
 // Classify peeks at the connection stream and determines protocol.
 // It must not consume bytes permanently.
 // The returned reader MUST be used for subsequent reads.
+//
+// Rules (deterministic, first 4 bytes):
+//
+//	bytes[2:4] == 0x0000          → Modbus (MBAP protocol ID is always 0)
+//	'R','I' + bytes[2:4] != 0     → Raw Ingest (Version, Area are never 0)
+//	0xA5,0x5A + bytes[2:4] != 0   → Raw Frame (Version is never 0)
+//	anything else                 → Unknown
+//
+// A Modbus client whose transaction ID happens to be 0x5249 ('R','I')
+// still carries protocol ID 0 and is therefore classified as Modbus.
 func Classify(conn net.Conn) (Protocol, *bufio.Reader, error) {
 	reader := bufio.NewReader(conn)
 
-	peek, err := reader.Peek(2)
+	peek, err := reader.Peek(classifyPeekLen)
 	if err != nil {
 		return ProtocolUnknown, reader, err
 	}
 
+	protoID := binary.BigEndian.Uint16(peek[2:4])
+
+	if protoID == 0 {
+		return ProtocolModbus, reader, nil
+	}
+
 	// Raw Ingest magic: 'R','I'
 	if peek[0] == 'R' && peek[1] == 'I' {
 		return ProtocolRawIngest, reader, nil
 	}
 
-	// Default to Modbus (MBAP TransactionID is arbitrary)
-	return ProtocolModbus, reader, nil
+	// Raw Frame magic: 0xA55A
+	if peek[0] == 0xA5 && peek[1] == 0x5A {
+		return ProtocolRawFrame, reader, nil
+	}
+
+	return ProtocolUnknown, reader, nil
 }
@@ -0,0 +1,122 @@
+// internal/ingress/firewall.go
+package ingress
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// Firewall is the ingress-level hard gate.
+// It is protocol-agnostic and runs BEFORE classification.
+//
+// Evaluation order:
+//  1. deny list match  -> reject
+//  2. allow list empty -> accept
+//  3. allow list match -> accept
+//  4. otherwise        -> reject
+type Firewall struct {
+	allow []netip.Prefix
+	deny  []netip.Prefix
+}
+
+// NewFirewall builds a firewall from CIDR or bare IP lists.
+// Bare IPs are treated as /32 (IPv4) or /128 (IPv6).
+func NewFirewall(allow, deny []string) (*Firewall, error) {
+	a, err := parsePrefixes(allow)
+	if err != nil {
+		return nil, fmt.Errorf("allow: %w", err)
+	}
+	d, err := parsePrefixes(deny)
+	if err != nil {
+		return nil, fmt.Errorf("deny: %w", err)
+	}
+	return &Firewall{allow: a, deny: d}, nil
+}
+
+// Permit decides whether a source address may connect.
+// The returned reason is intended for logging.
+func (f *Firewall) Permit(addr netip.Addr) (bool, string) {
+	if f == nil {
+		return true, "no firewall"
+	}
+	if !addr.IsValid() {
+		return false, "invalid source address"
+	}
+
+	addr = addr.Unmap()
+
+	for _, p := range f.deny {
+		if p.Contains(addr) {
+			return false, "deny " + p.String()
+		}
+	}
+
+	if len(f.allow) == 0 {
+		return true, "no allow list"
+	}
+
+	for _, p := range f.allow {
+		if p.Contains(addr) {
+			return true, "allow " + p.String()
+		}
+	}
+
+	return false, "no allow match"
+}
+
+// remoteAddr extracts the source IP of a connection.
+func remoteAddr(addr net.Addr) netip.Addr {
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		ip, _ := netip.AddrFromSlice(a.IP)
+		return ip.Unmap()
+	case *net.UDPAddr:
+		ip, _ := netip.AddrFromSlice(a.IP)
+		return ip.Unmap()
+	default:
+		return netip.Addr{}
+	}
+}
+
+func parsePrefixes(items []string) ([]netip.Prefix, error) {
+	out := make([]netip.Prefix, 0, len(items))
+
+	for _, raw := range items {
+		s := strings.TrimSpace(raw)
+		if s == "" {
+			continue
+		}
+
+		if strings.Contains(s, "/") {
+			p, err := netip.ParsePrefix(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cidr %q: %w", s, err)
+			}
+			out = append(out, netip.PrefixFrom(p.Addr().Unmap(), unmappedBits(p)).Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ip %q: %w", s, err)
+		}
+		addr = addr.Unmap()
+		out = append(out, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+
+	return out, nil
+}
+
+// unmappedBits adjusts an IPv4-mapped IPv6 prefix length to its IPv4 form.
+func unmappedBits(p netip.Prefix) int {
+	if p.Addr().Is4In6() {
+		bits := p.Bits() - 96
+		if bits < 0 {
+			bits = 0
+		}
+		return bits
+	}
+	return p.Bits()
+}
@@ -3,8 +3,12 @@ package ingress
 
 import (
 	"bufio"
+	"fmt"
 	"log"
 	"net"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"MMA2.0/internal/config"
 )
@@ -19,21 +23,75 @@ func (c *bufferedConn) Read(p []byte) (int, error) {
 	return c.r.Read(p)
 }
 
+// DefaultClassifyTimeout bounds how long a connection may stay silent
+// before classification gives up and closes it.
+const DefaultClassifyTimeout = 10 * time.Second
+
 // Listener represents a TCP ingress gate.
 type Listener struct {
 	cfg config.IngressGate
+	fw  *Firewall
+
+	// pinned skips classification when set (listener "protocol:").
+	pinned          Protocol
+	classifyTimeout time.Duration
+
+	// rejected counts connections dropped by the firewall.
+	rejected atomic.Uint64
 }
 
 // NewListener creates a new ingress listener.
-func NewListener(cfg config.IngressGate) *Listener {
-	return &Listener{cfg: cfg}
+func NewListener(cfg config.IngressGate) (*Listener, error) {
+	fw, err := NewFirewall(cfg.Allow, cfg.Deny)
+	if err != nil {
+		return nil, fmt.Errorf("ingress %s: firewall: %w", cfg.ID, err)
+	}
+
+	l := &Listener{
+		cfg:             cfg,
+		fw:              fw,
+		classifyTimeout: DefaultClassifyTimeout,
+	}
+
+	// Ingest listeners serve raw frames only: no sniffing.
+	if cfg.IsIngest() {
+		l.pinned = ProtocolRawFrame
+	}
+
+	if strings.TrimSpace(cfg.Protocol) != "" {
+		p, err := ParseProtocol(cfg.Protocol)
+		if err != nil {
+			return nil, fmt.Errorf("ingress %s: %w", cfg.ID, err)
+		}
+		l.pinned = p
+	}
+
+	if s := strings.TrimSpace(cfg.ClassifyTimeout); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("ingress %s: invalid classify_timeout %q", cfg.ID, cfg.ClassifyTimeout)
+		}
+		l.classifyTimeout = d
+	}
+
+	return l, nil
+}
+
+// Rejected returns the number of connections dropped by the firewall.
+func (l *Listener) Rejected() uint64 {
+	return l.rejected.Load()
+}
+
+// Handlers are the per-protocol connection handlers of a listener.
+// A handler owns the connection it is given (it must close it).
+type Handlers struct {
+	Modbus    func(net.Conn)
+	RawIngest func(net.Conn)
+	RawFrame  func(net.Conn)
 }
 
 // ListenAndServe starts the TCP listener and dispatches connections.
-func (l *Listener) ListenAndServe(
-	onModbus func(net.Conn),
-	onRawIngest func(net.Conn),
-) error {
+func (l *Listener) ListenAndServe(h Handlers) error {
 	ln, err := net.Listen("tcp", l.cfg.Listen)
 	if err != nil {
 		return err
@@ -47,37 +105,89 @@ func (l *Listener) ListenAndServe(
 			continue
 		}
 
-		go l.handleConn(conn, onModbus, onRawIngest)
+		go l.handleConn(conn, h)
 	}
 }
 
-func (l *Listener) handleConn(
-	conn net.Conn,
-	onModbus func(net.Conn),
-	onRawIngest func(net.Conn),
-) {
-	proto, reader, err := Classify(conn)
-	if err != nil {
+func (l *Listener) handleConn(conn net.Conn, h Handlers) {
+	// --------------------
+	// FIREWALL (before any protocol byte is read)
+	// --------------------
+	src := remoteAddr(conn.RemoteAddr())
+	if ok, reason := l.fw.Permit(src); !ok {
+		n := l.rejected.Add(1)
+		log.Printf("ingress %s: firewall rejected %s (%s) [rejected=%d]", l.cfg.ID, conn.RemoteAddr(), reason, n)
 		conn.Close()
 		return
 	}
 
-	// Important: after Peek(), all subsequent reads must use reader.
-	bc := &bufferedConn{Conn: conn, r: reader}
+	proto, nc, err := l.classify(conn)
+	if err != nil {
+		conn.Close()
+		return
+	}
 
 	switch proto {
 	case ProtocolModbus:
-		// Modbus is always enabled
-		onModbus(bc)
+		if !l.cfg.ModbusEnabled() {
+			log.Printf("ingress %s: modbus disabled, closing %s", l.cfg.ID, conn.RemoteAddr())
+			conn.Close()
+			return
+		}
+		h.Modbus(nc)
 		return
 
 	case ProtocolRawIngest:
-		// Raw ingest is always enabled
-		onRawIngest(bc)
+		if !l.cfg.RawIngestEnabled() {
+			log.Printf("ingress %s: raw_ingest disabled, closing %s", l.cfg.ID, conn.RemoteAddr())
+			conn.Close()
+			return
+		}
+		h.RawIngest(nc)
+		return
+
+	case ProtocolRawFrame:
+		if !l.cfg.RawFrameEnabled() || h.RawFrame == nil {
+			log.Printf("ingress %s: raw_frame disabled, closing %s", l.cfg.ID, conn.RemoteAddr())
+			conn.Close()
+			return
+		}
+		h.RawFrame(nc)
 		return
 
 	default:
-		// Unknown protocol → close
-		conn.Close()
+		// Unknown protocol → close, unless legacy Modbus fallback applies
+		if l.cfg.DiscardUnknown || !l.cfg.ModbusEnabled() {
+			log.Printf("ingress %s: unknown protocol, closing %s", l.cfg.ID, conn.RemoteAddr())
+			conn.Close()
+			return
+		}
+		h.Modbus(nc)
+	}
+}
+
+// classify selects the protocol for a connection.
+// Pinned listeners skip sniffing; otherwise the first bytes are peeked
+// under classifyTimeout.
+func (l *Listener) classify(conn net.Conn) (Protocol, net.Conn, error) {
+	if l.pinned != ProtocolUnknown {
+		return l.pinned, conn, nil
+	}
+
+	if err := conn.SetReadDeadline(time.Now().Add(l.classifyTimeout)); err != nil {
+		return ProtocolUnknown, nil, err
+	}
+
+	proto, reader, err := Classify(conn)
+	if err != nil {
+		log.Printf("ingress %s: classification failed for %s: %v", l.cfg.ID, conn.RemoteAddr(), err)
+		return ProtocolUnknown, nil, err
+	}
+
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		return ProtocolUnknown, nil, err
 	}
+
+	// Important: after Peek(), all subsequent reads must use reader.
+	return proto, &bufferedConn{Conn: conn, r: reader}, nil
 }
@@ -0,0 +1,89 @@
+// internal/ingress/udp_listener.go
+package ingress
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/netip"
+	"sync/atomic"
+	"time"
+
+	"MMA2.0/internal/config"
+)
+
+// maxDatagram is the largest UDP payload read in one call.
+const maxDatagram = 65535
+
+// firewallLogInterval rate-limits firewall rejection logs: datagram
+// sources can be spoofed, so one line per datagram could flood the log.
+const firewallLogInterval = 10 * time.Second
+
+// DatagramHandler processes one datagram from src.
+// A non-nil return value is sent back to the sender.
+type DatagramHandler func(data []byte, src netip.Addr) []byte
+
+// UDPListener represents a UDP ingress gate (kind: udp_ingest).
+// There is no classification: every datagram goes to the handler.
+type UDPListener struct {
+	cfg config.IngressGate
+	fw  *Firewall
+
+	// rejected counts datagrams dropped by the firewall.
+	rejected atomic.Uint64
+}
+
+// NewUDPListener creates a new UDP ingress listener.
+func NewUDPListener(cfg config.IngressGate) (*UDPListener, error) {
+	fw, err := NewFirewall(cfg.Allow, cfg.Deny)
+	if err != nil {
+		return nil, fmt.Errorf("ingress %s: firewall: %w", cfg.ID, err)
+	}
+	return &UDPListener{cfg: cfg, fw: fw}, nil
+}
+
+// Rejected returns the number of datagrams dropped by the firewall.
+func (l *UDPListener) Rejected() uint64 {
+	return l.rejected.Load()
+}
+
+// ListenAndServe binds the UDP socket and dispatches datagrams.
+// Datagrams are handled in arrival order on a single goroutine.
+func (l *UDPListener) ListenAndServe(h DatagramHandler) error {
+	pc, err := net.ListenPacket("udp", l.cfg.Listen)
+	if err != nil {
+		return err
+	}
+	defer pc.Close()
+
+	log.Printf("ingress %s listening on udp %s", l.cfg.ID, l.cfg.Listen)
+
+	buf := make([]byte, maxDatagram)
+	var lastLog time.Time
+
+	for {
+		n, addr, err := pc.ReadFrom(buf)
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				continue
+			}
+			return err
+		}
+
+		src := remoteAddr(addr)
+		if ok, reason := l.fw.Permit(src); !ok {
+			r := l.rejected.Add(1)
+			if now := time.Now(); now.Sub(lastLog) >= firewallLogInterval {
+				lastLog = now
+				log.Printf("ingress %s: firewall rejected datagram from %s (%s) [rejected=%d]", l.cfg.ID, addr, reason, r)
+			}
+			continue
+		}
+
+		if reply := h(buf[:n], src); reply != nil {
+			if _, err := pc.WriteTo(reply, addr); err != nil {
+				log.Printf("ingress %s: reply to %s failed: %v", l.cfg.ID, addr, err)
+			}
+		}
+	}
+}
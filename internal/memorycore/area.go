@@ -1,6 +1,11 @@
 // internal/memorycore/area.go
 package memorycore
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Area uint8
 
 const (
@@ -10,14 +15,21 @@ const (
 	AreaDiscreteInputs Area = 2
 	AreaHoldingRegs    Area = 3
 	AreaInputRegs      Area = 4
+
+	// AreaFileRecords is the optional file-record area (Modbus FC20/21).
+	// Generic area APIs address it as a flat word range:
+	//   address = (file-1)*records + record
+	// File-aware access goes through FileRecordAddress.
+	AreaFileRecords Area = 5
 )
 
 func (a Area) IsBitArea() bool {
 	return a == AreaCoils || a == AreaDiscreteInputs
 }
 
+// IsRegArea reports word-encoded areas (registers and file records).
 func (a Area) IsRegArea() bool {
-	return a == AreaHoldingRegs || a == AreaInputRegs
+	return a == AreaHoldingRegs || a == AreaInputRegs || a == AreaFileRecords
 }
 
 func (a Area) String() string {
@@ -30,7 +42,27 @@ func (a Area) String() string {
 		return "holding_registers"
 	case AreaInputRegs:
 		return "input_registers"
+	case AreaFileRecords:
+		return "file_records"
 	default:
 		return "invalid"
 	}
 }
+
+// ParseArea maps an area name (as returned by String) to an Area.
+func ParseArea(s string) (Area, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "coils":
+		return AreaCoils, nil
+	case "discrete_inputs":
+		return AreaDiscreteInputs, nil
+	case "holding_registers":
+		return AreaHoldingRegs, nil
+	case "input_registers":
+		return AreaInputRegs, nil
+	case "file_records":
+		return AreaFileRecords, nil
+	default:
+		return AreaInvalid, fmt.Errorf("%w %q", ErrInvalidArea, s)
+	}
+}
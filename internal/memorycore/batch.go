@@ -0,0 +1,161 @@
+// internal/memorycore/batch.go
+package memorycore
+
+import "encoding/binary"
+
+// Write is one element of a transactional multi-write.
+//
+// Src encoding follows the single-write API:
+//   - Bit areas: packed bits (LSB-first), bytes >= ceil(count/8)
+//   - Reg areas: big-endian uint16 words, bytes >= count*2
+type Write struct {
+	Area    Area
+	Address uint16
+	Count   uint16
+	Src     []byte
+}
+
+// ApplyWrites applies all writes atomically under a single memory lock.
+//
+// All-or-nothing:
+//   - every write is validated before any byte is modified
+//   - on the first validation failure, memory is unchanged
+//   - readers never observe a partially applied batch
+//
+// The returned index identifies the failing write (-1 on success).
+func (m *Memory) ApplyWrites(writes []Write) (int, error) {
+	if m == nil {
+		return -1, ErrNilMemory
+	}
+	if len(writes) == 0 {
+		return -1, ErrCountZero
+	}
+
+	// --------------------
+	// Validate (no lock needed: layouts are immutable)
+	// --------------------
+	for i, w := range writes {
+		if err := m.validateWrite(w); err != nil {
+			return i, err
+		}
+	}
+
+	// --------------------
+	// Apply (single lock)
+	// --------------------
+	m.mu.Lock()
+	for _, w := range writes {
+		m.applyWriteLocked(w)
+	}
+	m.mu.Unlock()
+
+	return -1, nil
+}
+
+func (m *Memory) validateWrite(w Write) error {
+	if w.Count == 0 {
+		return ErrCountZero
+	}
+
+	layout, err := m.layoutFor(w.Area)
+	if err != nil {
+		return err
+	}
+	if !layout.Contains(w.Address, w.Count) {
+		return ErrOutOfBounds
+	}
+
+	want := int(w.Count) * 2
+	if w.Area.IsBitArea() {
+		want = bytesForBits(w.Count)
+	}
+	if len(w.Src) < want {
+		return ErrSrcTooSmall
+	}
+
+	return nil
+}
+
+// applyWriteLocked applies a validated write. Caller holds m.mu.
+func (m *Memory) applyWriteLocked(w Write) {
+	switch w.Area {
+	case AreaCoils:
+		writeBits(m.coilsBits, m.coilsLayout.Offset(w.Address), w.Count, w.Src)
+	case AreaDiscreteInputs:
+		writeBits(m.discreteInputsBits, m.discreteInputsLayout.Offset(w.Address), w.Count, w.Src)
+	case AreaHoldingRegs:
+		putRegs(m.holdingRegs, m.holdingRegsLayout.Offset(w.Address), w.Count, w.Src)
+	case AreaInputRegs:
+		putRegs(m.inputRegs, m.inputRegsLayout.Offset(w.Address), w.Count, w.Src)
+	case AreaFileRecords:
+		putRegs(m.fileRecords, m.fileRecordsLayout.Offset(w.Address), w.Count, w.Src)
+	}
+}
+
+// layoutFor returns the layout of an area, or the matching error.
+func (m *Memory) layoutFor(area Area) (*AreaLayout, error) {
+	var layout *AreaLayout
+
+	switch area {
+	case AreaCoils:
+		layout = m.coilsLayout
+	case AreaDiscreteInputs:
+		layout = m.discreteInputsLayout
+	case AreaHoldingRegs:
+		layout = m.holdingRegsLayout
+	case AreaInputRegs:
+		layout = m.inputRegsLayout
+	case AreaFileRecords:
+		layout = m.fileRecordsLayout
+	default:
+		return nil, ErrInvalidArea
+	}
+
+	if layout == nil {
+		return nil, ErrAreaNotDefined
+	}
+	return layout, nil
+}
+
+func putRegs(dst []uint16, off uint16, count uint16, src []byte) {
+	for i := uint16(0); i < count; i++ {
+		dst[int(off+i)] = binary.BigEndian.Uint16(src[int(i)*2 : int(i)*2+2])
+	}
+}
+
+// WriteReadRegs writes holding registers, then reads holding registers,
+// under a single memory lock (Modbus FC23 semantics).
+//
+// Both ranges are validated before the write; on error memory is unchanged.
+// The read observes the write when the ranges overlap.
+func (m *Memory) WriteReadRegs(writeAddress, writeCount uint16, src []byte, readAddress, readCount uint16, dst []byte) error {
+	if m == nil {
+		return ErrNilMemory
+	}
+
+	w := Write{Area: AreaHoldingRegs, Address: writeAddress, Count: writeCount, Src: src}
+	if err := m.validateWrite(w); err != nil {
+		return err
+	}
+
+	if readCount == 0 {
+		return ErrCountZero
+	}
+	if len(dst) < int(readCount)*2 {
+		return ErrDstTooSmall
+	}
+	if !m.holdingRegsLayout.Contains(readAddress, readCount) {
+		return ErrOutOfBounds
+	}
+
+	off := m.holdingRegsLayout.Offset(readAddress)
+
+	m.mu.Lock()
+	m.applyWriteLocked(w)
+	for i := uint16(0); i < readCount; i++ {
+		binary.BigEndian.PutUint16(dst[int(i)*2:int(i)*2+2], m.holdingRegs[int(off+i)])
+	}
+	m.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,92 @@
+// internal/memorycore/batch_test.go
+package memorycore
+
+import (
+	"errors"
+	"testing"
+)
+
+func newBatchMemory(t *testing.T) *Memory {
+	t.Helper()
+	m, err := NewMemory(MemoryLayouts{
+		Coils:       &AreaLayout{Start: 0, Size: 16},
+		HoldingRegs: &AreaLayout{Start: 100, Size: 4},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func snapshot(t *testing.T, m *Memory) (coils, regs []byte) {
+	t.Helper()
+	coils = make([]byte, 2)
+	if err := m.ReadBits(AreaCoils, 0, 16, coils); err != nil {
+		t.Fatal(err)
+	}
+	regs = make([]byte, 8)
+	if err := m.ReadRegs(AreaHoldingRegs, 100, 4, regs); err != nil {
+		t.Fatal(err)
+	}
+	return coils, regs
+}
+
+func TestApplyWritesAll(t *testing.T) {
+	m := newBatchMemory(t)
+
+	idx, err := m.ApplyWrites([]Write{
+		{Area: AreaCoils, Address: 0, Count: 8, Src: []byte{0xA5}},
+		{Area: AreaHoldingRegs, Address: 101, Count: 2, Src: []byte{0x12, 0x34, 0x56, 0x78}},
+	})
+	if err != nil || idx != -1 {
+		t.Fatalf("ApplyWrites = (%d, %v), want (-1, nil)", idx, err)
+	}
+
+	coils, regs := snapshot(t, m)
+	if want := []byte{0xA5, 0}; string(coils) != string(want) {
+		t.Fatalf("coils = % x, want % x", coils, want)
+	}
+	if want := []byte{0, 0, 0x12, 0x34, 0x56, 0x78, 0, 0}; string(regs) != string(want) {
+		t.Fatalf("regs = % x, want % x", regs, want)
+	}
+}
+
+func TestApplyWritesAllOrNothing(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		bad  Write
+		err  error
+	}{
+		{"out of bounds", Write{Area: AreaHoldingRegs, Address: 103, Count: 2, Src: make([]byte, 4)}, ErrOutOfBounds},
+		{"area not defined", Write{Area: AreaInputRegs, Address: 0, Count: 1, Src: make([]byte, 2)}, ErrAreaNotDefined},
+		{"invalid area", Write{Area: Area(9), Address: 0, Count: 1, Src: make([]byte, 2)}, ErrInvalidArea},
+		{"count zero", Write{Area: AreaCoils, Address: 0, Count: 0}, ErrCountZero},
+		{"short src", Write{Area: AreaHoldingRegs, Address: 100, Count: 2, Src: make([]byte, 3)}, ErrSrcTooSmall},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newBatchMemory(t)
+
+			// Valid writes on both sides of the failing one.
+			idx, err := m.ApplyWrites([]Write{
+				{Area: AreaCoils, Address: 0, Count: 8, Src: []byte{0xFF}},
+				tc.bad,
+				{Area: AreaHoldingRegs, Address: 100, Count: 1, Src: []byte{0xBE, 0xEF}},
+			})
+			if !errors.Is(err, tc.err) || idx != 1 {
+				t.Fatalf("ApplyWrites = (%d, %v), want (1, %v)", idx, err, tc.err)
+			}
+
+			coils, regs := snapshot(t, m)
+			if string(coils) != string(make([]byte, 2)) || string(regs) != string(make([]byte, 8)) {
+				t.Fatalf("memory changed: coils % x, regs % x", coils, regs)
+			}
+		})
+	}
+}
+
+func TestApplyWritesEmpty(t *testing.T) {
+	m := newBatchMemory(t)
+	if _, err := m.ApplyWrites(nil); !errors.Is(err, ErrCountZero) {
+		t.Fatalf("err = %v, want %v", err, ErrCountZero)
+	}
+}
@@ -0,0 +1,54 @@
+// internal/memorycore/device_identity.go
+package memorycore
+
+import "errors"
+
+// Device identification object IDs (Modbus FC43 / MEI 0x0E).
+//
+//	0x00..0x02 basic (mandatory)
+//	0x03..0x7F regular (0x03..0x06 standard, the rest reserved)
+//	0x80..0xFF extended (private)
+const (
+	DeviceObjectVendorName          = uint8(0x00)
+	DeviceObjectProductCode         = uint8(0x01)
+	DeviceObjectRevision            = uint8(0x02)
+	DeviceObjectVendorURL           = uint8(0x03)
+	DeviceObjectProductName         = uint8(0x04)
+	DeviceObjectModelName           = uint8(0x05)
+	DeviceObjectUserApplicationName = uint8(0x06)
+
+	DeviceObjectFirstPrivate = uint8(0x80)
+)
+
+// ErrMissingBasicObject reports an identity without all basic objects.
+var ErrMissingBasicObject = errors.New("device identity: basic objects (vendor, product code, revision) are required")
+
+// DeviceIdentity is the optional identification of a memory, served to
+// Modbus clients. It is metadata, not memory: immutable after startup and
+// never addressed by reads or writes.
+type DeviceIdentity struct {
+	Objects map[uint8][]byte
+}
+
+// SetDeviceIdentity attaches an identity to this memory.
+// Intended for startup config load only.
+func (m *Memory) SetDeviceIdentity(id *DeviceIdentity) error {
+	if m == nil {
+		return ErrNilMemory
+	}
+	for _, oid := range []uint8{DeviceObjectVendorName, DeviceObjectProductCode, DeviceObjectRevision} {
+		if _, ok := id.Objects[oid]; !ok {
+			return ErrMissingBasicObject
+		}
+	}
+	m.deviceIdentity = id
+	return nil
+}
+
+// DeviceIdentity returns the identity, if present.
+func (m *Memory) DeviceIdentity() *DeviceIdentity {
+	if m == nil {
+		return nil
+	}
+	return m.deviceIdentity
+}
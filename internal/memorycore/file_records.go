@@ -0,0 +1,44 @@
+// internal/memorycore/file_records.go
+package memorycore
+
+// FileRecordAddress maps (file, record, count) to the flat AreaFileRecords
+// address used by the generic area APIs. The range must stay within one file.
+func (m *Memory) FileRecordAddress(file, record, count uint16) (uint16, error) {
+	if m == nil {
+		return 0, ErrNilMemory
+	}
+	if m.fileRecordsLayout == nil {
+		return 0, ErrAreaNotDefined
+	}
+	if count == 0 {
+		return 0, ErrCountZero
+	}
+
+	files := m.fileRecordsLayout.Size / m.fileRecordLen
+	if file == 0 || file > files {
+		return 0, ErrOutOfBounds
+	}
+	if uint32(record)+uint32(count) > uint32(m.fileRecordLen) {
+		return 0, ErrOutOfBounds
+	}
+
+	return (file-1)*m.fileRecordLen + record, nil
+}
+
+// ReadFileRecords reads count records of one file (big-endian words).
+func (m *Memory) ReadFileRecords(file, record, count uint16, dst []byte) error {
+	addr, err := m.FileRecordAddress(file, record, count)
+	if err != nil {
+		return err
+	}
+	return m.ReadRegs(AreaFileRecords, addr, count, dst)
+}
+
+// WriteFileRecords writes count records of one file (big-endian words).
+func (m *Memory) WriteFileRecords(file, record, count uint16, src []byte) error {
+	addr, err := m.FileRecordAddress(file, record, count)
+	if err != nil {
+		return err
+	}
+	return m.WriteRegs(AreaFileRecords, addr, count, src)
+}
@@ -0,0 +1,126 @@
+// internal/memorycore/file_records_test.go
+package memorycore
+
+import (
+	"errors"
+	"testing"
+)
+
+// newFileMemory creates a memory with 3 files of 10 records each
+// (flat addresses 0..29: file 1 = 0..9, file 2 = 10..19, file 3 = 20..29).
+func newFileMemory(t *testing.T) *Memory {
+	t.Helper()
+	m, err := NewMemory(MemoryLayouts{
+		FileRecords: &FileLayout{Files: 3, Records: 10},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestFileLayoutValidate(t *testing.T) {
+	for _, tc := range []struct {
+		l    FileLayout
+		want error
+	}{
+		{FileLayout{Files: 1, Records: 1}, nil},
+		{FileLayout{Files: 6, Records: MaxFileRecords}, nil},
+		{FileLayout{Files: 0, Records: 10}, ErrSizeZero},
+		{FileLayout{Files: 10, Records: 0}, ErrSizeZero},
+		{FileLayout{Files: 1, Records: MaxFileRecords + 1}, ErrOutOfBounds},
+		{FileLayout{Files: 7, Records: MaxFileRecords}, ErrStartOverflow},
+	} {
+		if err := tc.l.Validate(); !errors.Is(err, tc.want) {
+			t.Errorf("%+v: err = %v, want %v", tc.l, err, tc.want)
+		}
+	}
+}
+
+func TestFileRecordAddress(t *testing.T) {
+	m := newFileMemory(t)
+
+	for _, tc := range []struct {
+		file, record, count uint16
+		want                uint16
+		err                 error
+	}{
+		{1, 0, 1, 0, nil},
+		{1, 0, 10, 0, nil},
+		{2, 0, 1, 10, nil},
+		{2, 3, 4, 13, nil},
+		{3, 9, 1, 29, nil},
+
+		{0, 0, 1, 0, ErrOutOfBounds},  // files are numbered from 1
+		{4, 0, 1, 0, ErrOutOfBounds},  // no such file
+		{1, 9, 2, 0, ErrOutOfBounds},  // would cross into file 2
+		{1, 10, 1, 0, ErrOutOfBounds}, // record past the file
+		{1, 0, 0, 0, ErrCountZero},
+	} {
+		got, err := m.FileRecordAddress(tc.file, tc.record, tc.count)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("(%d,%d,%d): err = %v, want %v", tc.file, tc.record, tc.count, err, tc.err)
+			continue
+		}
+		if err == nil && got != tc.want {
+			t.Errorf("(%d,%d,%d) = %d, want %d", tc.file, tc.record, tc.count, got, tc.want)
+		}
+	}
+
+	plain, err := NewMemory(MemoryLayouts{HoldingRegs: &AreaLayout{Start: 0, Size: 4}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := plain.FileRecordAddress(1, 0, 1); !errors.Is(err, ErrAreaNotDefined) {
+		t.Errorf("no file area: err = %v, want %v", err, ErrAreaNotDefined)
+	}
+}
+
+func TestFileRecordsFlatAddressing(t *testing.T) {
+	m := newFileMemory(t)
+
+	// File records and the flat area are the same storage.
+	if err := m.WriteFileRecords(2, 3, 2, []byte{0x12, 0x34, 0x56, 0x78}); err != nil {
+		t.Fatal(err)
+	}
+	flat := make([]byte, 8)
+	if err := m.ReadRegs(AreaFileRecords, 12, 4, flat); err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0, 0, 0x12, 0x34, 0x56, 0x78, 0, 0}; string(flat) != string(want) {
+		t.Fatalf("flat 12..15 = % x, want % x", flat, want)
+	}
+
+	// A flat write (raw ingest fill) is visible as records of file 3.
+	if _, err := m.ApplyWrites([]Write{{Area: AreaFileRecords, Address: 28, Count: 2, Src: []byte{0xAA, 0xBB, 0xCC, 0xDD}}}); err != nil {
+		t.Fatal(err)
+	}
+	rec := make([]byte, 4)
+	if err := m.ReadFileRecords(3, 8, 2, rec); err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0xAA, 0xBB, 0xCC, 0xDD}; string(rec) != string(want) {
+		t.Fatalf("file 3 records 8..9 = % x, want % x", rec, want)
+	}
+
+	// The flat area ends with the last file.
+	if err := m.ReadRegs(AreaFileRecords, 29, 2, make([]byte, 4)); !errors.Is(err, ErrOutOfBounds) {
+		t.Fatalf("past the area: err = %v, want %v", err, ErrOutOfBounds)
+	}
+}
+
+func TestWriteFileRecordsOutOfRange(t *testing.T) {
+	m := newFileMemory(t)
+
+	// Crossing a file boundary is rejected, nothing is written.
+	if err := m.WriteFileRecords(1, 9, 2, []byte{1, 1, 2, 2}); !errors.Is(err, ErrOutOfBounds) {
+		t.Fatalf("err = %v, want %v", err, ErrOutOfBounds)
+	}
+	flat := make([]byte, 4)
+	if err := m.ReadRegs(AreaFileRecords, 9, 2, flat); err != nil {
+		t.Fatal(err)
+	}
+	if string(flat) != string(make([]byte, 4)) {
+		t.Fatalf("flat 9..10 = % x, want zeros", flat)
+	}
+}
@@ -34,3 +34,26 @@ func (l AreaLayout) Contains(address uint16, count uint16) bool {
 func (l AreaLayout) Offset(address uint16) uint16 {
 	return uint16(uint32(address) - uint32(l.Start))
 }
+
+// FileLayout is the shape of a file-record area:
+// Files files (numbered 1..Files) of Records 16-bit records (0..Records-1).
+type FileLayout struct {
+	Files   uint16
+	Records uint16
+}
+
+// MaxFileRecords is the record limit per file (Modbus record numbers 0..9999).
+const MaxFileRecords = 10000
+
+func (l FileLayout) Validate() error {
+	if l.Files == 0 || l.Records == 0 {
+		return ErrSizeZero
+	}
+	if l.Records > MaxFileRecords {
+		return ErrOutOfBounds
+	}
+	if uint32(l.Files)*uint32(l.Records) > 0xFFFF {
+		return ErrStartOverflow
+	}
+	return nil
+}
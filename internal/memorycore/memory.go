@@ -4,6 +4,7 @@ package memorycore
 import (
 	"encoding/binary"
 	"sync"
+	"sync/atomic"
 )
 
 type MemoryLayouts struct {
@@ -11,6 +12,9 @@ type MemoryLayouts struct {
 	DiscreteInputs *AreaLayout
 	HoldingRegs    *AreaLayout
 	InputRegs      *AreaLayout
+
+	// Optional file-record area (FC20/21).
+	FileRecords *FileLayout
 }
 
 type Memory struct {
@@ -27,8 +31,17 @@ type Memory struct {
 	holdingRegs []uint16
 	inputRegs   []uint16
 
-	// ---- State Sealing metadata (no behavior here) ----
+	// File records: flat words, file f (1-based) at (f-1)*fileRecordLen.
+	fileRecordsLayout *AreaLayout
+	fileRecordLen     uint16
+	fileRecords       []uint16
+
+	// ---- State Sealing ----
 	stateSealing *StateSealingDef
+	lifecycle    atomic.Uint32 // Lifecycle; zero value = RUN
+
+	// ---- Device identification (FC43/14) ----
+	deviceIdentity *DeviceIdentity
 }
 
 func NewMemory(layouts MemoryLayouts) (*Memory, error) {
@@ -66,6 +79,16 @@ func NewMemory(layouts MemoryLayouts) (*Memory, error) {
 		m.inputRegs = make([]uint16, layouts.InputRegs.Size)
 	}
 
+	if layouts.FileRecords != nil {
+		if err := layouts.FileRecords.Validate(); err != nil {
+			return nil, err
+		}
+		size := layouts.FileRecords.Files * layouts.FileRecords.Records
+		m.fileRecordsLayout = &AreaLayout{Start: 0, Size: size}
+		m.fileRecordLen = layouts.FileRecords.Records
+		m.fileRecords = make([]uint16, size)
+	}
+
 	return m, nil
 }
 
@@ -178,6 +201,9 @@ func (m *Memory) ReadRegs(area Area, address uint16, count uint16, dst []byte) e
 	case AreaInputRegs:
 		layout = m.inputRegsLayout
 		backing = m.inputRegs
+	case AreaFileRecords:
+		layout = m.fileRecordsLayout
+		backing = m.fileRecords
 	default:
 		return ErrInvalidArea
 	}
@@ -224,6 +250,9 @@ func (m *Memory) WriteRegs(area Area, address uint16, count uint16, src []byte)
 	case AreaInputRegs:
 		layout = m.inputRegsLayout
 		backing = m.inputRegs
+	case AreaFileRecords:
+		layout = m.fileRecordsLayout
+		backing = m.fileRecords
 	default:
 		return ErrInvalidArea
 	}
@@ -246,3 +275,32 @@ func (m *Memory) WriteRegs(area Area, address uint16, count uint16, src []byte)
 
 	return nil
 }
+
+// MaskWriteReg applies a Modbus FC22 mask to one holding register under
+// the write lock:
+//
+//	result = (current AND andMask) OR (orMask AND NOT andMask)
+//
+// It returns the resulting register value.
+func (m *Memory) MaskWriteReg(address uint16, andMask, orMask uint16) (uint16, error) {
+	if m == nil {
+		return 0, ErrNilMemory
+	}
+
+	layout := m.holdingRegsLayout
+	if layout == nil {
+		return 0, ErrAreaNotDefined
+	}
+	if !layout.Contains(address, 1) {
+		return 0, ErrOutOfBounds
+	}
+
+	i := int(layout.Offset(address))
+
+	m.mu.Lock()
+	v := (m.holdingRegs[i] & andMask) | (orMask &^ andMask)
+	m.holdingRegs[i] = v
+	m.mu.Unlock()
+
+	return v, nil
+}
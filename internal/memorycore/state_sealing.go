@@ -1,22 +1,120 @@
 // internal/memorycore/state_sealing.go
 package memorycore
 
+// Lifecycle is the per-memory State Sealing lifecycle state.
+//
+// Contract (LOCKED, see docs/01_STATE_SEALING.md):
+//   - Memories without state sealing start (and stay) in RUN
+//   - Memories with state sealing start in PRE-RUN
+//   - PRE-RUN -> RUN is the ONLY transition
+//   - RUN -> PRE-RUN is forbidden (restart is the only way back)
+type Lifecycle uint32
+
+const (
+	LifecycleRun    Lifecycle = 0
+	LifecyclePreRun Lifecycle = 1
+)
+
+func (l Lifecycle) String() string {
+	switch l {
+	case LifecycleRun:
+		return "RUN"
+	case LifecyclePreRun:
+		return "PRE-RUN"
+	default:
+		return "invalid"
+	}
+}
+
 // StateSealingDef describes where the sealing flag lives.
-// Semantics:
-//   0 = sealed
-//   1 = unsealed
+// Semantics (evaluated only while in PRE-RUN):
+//   0 = no effect
+//   1 = unlock (PRE-RUN -> RUN)
 type StateSealingDef struct {
 	Area    Area
 	Address uint16
 }
 
-// SetStateSealing attaches a state sealing definition to this memory.
-// Metadata only — no behavior.
-func (m *Memory) SetStateSealing(def StateSealingDef) {
+// SetStateSealing attaches a state sealing definition to this memory
+// and places it in PRE-RUN.
+// The flag must live in an allocated bit area (coils or discrete inputs).
+// Intended for startup config load only.
+func (m *Memory) SetStateSealing(def StateSealingDef) error {
+	if m == nil {
+		return ErrNilMemory
+	}
+
+	var layout *AreaLayout
+	switch def.Area {
+	case AreaCoils:
+		layout = m.coilsLayout
+	case AreaDiscreteInputs:
+		layout = m.discreteInputsLayout
+	default:
+		return ErrInvalidArea
+	}
+
+	if layout == nil {
+		return ErrAreaNotDefined
+	}
+	if !layout.Contains(def.Address, 1) {
+		return ErrOutOfBounds
+	}
+
 	m.stateSealing = &def
+	m.lifecycle.Store(uint32(LifecyclePreRun))
+	return nil
 }
 
 // StateSealing returns the sealing definition, if present.
 func (m *Memory) StateSealing() *StateSealingDef {
 	return m.stateSealing
 }
+
+// Lifecycle returns the current lifecycle state.
+func (m *Memory) Lifecycle() Lifecycle {
+	if m == nil {
+		return LifecycleRun
+	}
+	return Lifecycle(m.lifecycle.Load())
+}
+
+// ObserveIngestWrite evaluates the sealing flag against a bit write that was
+// just applied by an INGEST transport.
+//
+// It returns true only if this write performed the PRE-RUN -> RUN transition.
+//
+// Rules:
+//   - Only ingest transports may call this; Modbus never does
+//   - The flag is ignored once the memory is in RUN
+//   - Writing 0 has no effect
+//   - Memory contents are never mutated here
+func (m *Memory) ObserveIngestWrite(area Area, address uint16, count uint16, src []byte) bool {
+	if m == nil || m.stateSealing == nil {
+		return false
+	}
+	if m.Lifecycle() != LifecyclePreRun {
+		return false
+	}
+
+	seal := m.stateSealing
+	if area != seal.Area {
+		return false
+	}
+
+	layout := AreaLayout{Start: address, Size: count}
+	if !layout.Contains(seal.Address, 1) {
+		return false
+	}
+
+	bit := layout.Offset(seal.Address)
+	idx := int(bit / 8)
+	if idx >= len(src) {
+		return false
+	}
+	if src[idx]&byte(1<<(bit%8)) == 0 {
+		return false
+	}
+
+	return m.lifecycle.CompareAndSwap(uint32(LifecyclePreRun), uint32(LifecycleRun))
+}
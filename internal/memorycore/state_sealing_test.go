@@ -0,0 +1,135 @@
+// internal/memorycore/state_sealing_test.go
+package memorycore
+
+import (
+	"errors"
+	"testing"
+)
+
+func newSealedMemory(t *testing.T, def StateSealingDef) *Memory {
+	t.Helper()
+	m, err := NewMemory(MemoryLayouts{
+		Coils:          &AreaLayout{Start: 0, Size: 16},
+		DiscreteInputs: &AreaLayout{Start: 0, Size: 16},
+		HoldingRegs:    &AreaLayout{Start: 0, Size: 4},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetStateSealing(def); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestLifecycleStart(t *testing.T) {
+	m, err := NewMemory(MemoryLayouts{Coils: &AreaLayout{Start: 0, Size: 8}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Lifecycle(); got != LifecycleRun {
+		t.Fatalf("without sealing: %s, want RUN", got)
+	}
+
+	m = newSealedMemory(t, StateSealingDef{Area: AreaCoils, Address: 3})
+	if got := m.Lifecycle(); got != LifecyclePreRun {
+		t.Fatalf("with sealing: %s, want PRE-RUN", got)
+	}
+}
+
+func TestSetStateSealingInvalid(t *testing.T) {
+	m, err := NewMemory(MemoryLayouts{
+		Coils:       &AreaLayout{Start: 0, Size: 8},
+		HoldingRegs: &AreaLayout{Start: 0, Size: 8},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		def  StateSealingDef
+		want error
+	}{
+		{StateSealingDef{Area: AreaHoldingRegs, Address: 0}, ErrInvalidArea},
+		{StateSealingDef{Area: AreaDiscreteInputs, Address: 0}, ErrAreaNotDefined},
+		{StateSealingDef{Area: AreaCoils, Address: 8}, ErrOutOfBounds},
+	} {
+		if err := m.SetStateSealing(tc.def); !errors.Is(err, tc.want) {
+			t.Errorf("%+v: err = %v, want %v", tc.def, err, tc.want)
+		}
+	}
+	if got := m.Lifecycle(); got != LifecycleRun {
+		t.Fatalf("after rejected definitions: %s, want RUN", got)
+	}
+}
+
+func TestObserveIngestWriteUnlock(t *testing.T) {
+	m := newSealedMemory(t, StateSealingDef{Area: AreaCoils, Address: 10})
+
+	// Writes that must not unlock.
+	for _, tc := range []struct {
+		name    string
+		area    Area
+		address uint16
+		count   uint16
+		src     []byte
+	}{
+		{"zero at flag", AreaCoils, 10, 1, []byte{0x00}},
+		{"zero at flag in range", AreaCoils, 8, 8, []byte{0xFB}},
+		{"one next to flag", AreaCoils, 11, 1, []byte{0x01}},
+		{"range before flag", AreaCoils, 0, 10, []byte{0xFF, 0x03}},
+		{"other bit area", AreaDiscreteInputs, 10, 1, []byte{0x01}},
+		{"short source", AreaCoils, 0, 16, []byte{0xFF}},
+	} {
+		if m.ObserveIngestWrite(tc.area, tc.address, tc.count, tc.src) {
+			t.Errorf("%s: unlocked", tc.name)
+		}
+		if got := m.Lifecycle(); got != LifecyclePreRun {
+			t.Fatalf("%s: %s, want PRE-RUN", tc.name, got)
+		}
+	}
+
+	// A 1 at the flag location (bit 2 of a write starting at 8).
+	if !m.ObserveIngestWrite(AreaCoils, 8, 4, []byte{0x04}) {
+		t.Fatal("flag write did not unlock")
+	}
+	if got := m.Lifecycle(); got != LifecycleRun {
+		t.Fatalf("after unlock: %s, want RUN", got)
+	}
+
+	// Once in RUN the flag is ignored, in both directions.
+	if m.ObserveIngestWrite(AreaCoils, 10, 1, []byte{0x01}) {
+		t.Error("second unlock reported")
+	}
+	m.ObserveIngestWrite(AreaCoils, 10, 1, []byte{0x00})
+	if got := m.Lifecycle(); got != LifecycleRun {
+		t.Fatalf("after flag cleared: %s, want RUN", got)
+	}
+}
+
+func TestObserveIngestWriteDoesNotMutate(t *testing.T) {
+	m := newSealedMemory(t, StateSealingDef{Area: AreaDiscreteInputs, Address: 0})
+
+	if !m.ObserveIngestWrite(AreaDiscreteInputs, 0, 1, []byte{0x01}) {
+		t.Fatal("flag write did not unlock")
+	}
+
+	// Observation only: memory contents are written by ApplyWrites, not here.
+	dst := make([]byte, 1)
+	if err := m.ReadBits(AreaDiscreteInputs, 0, 1, dst); err != nil {
+		t.Fatal(err)
+	}
+	if dst[0] != 0 {
+		t.Fatalf("discrete input 0 = %d, want 0", dst[0])
+	}
+}
+
+func TestObserveIngestWriteWithoutSealing(t *testing.T) {
+	m, err := NewMemory(MemoryLayouts{Coils: &AreaLayout{Start: 0, Size: 8}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.ObserveIngestWrite(AreaCoils, 0, 8, []byte{0xFF}) {
+		t.Fatal("unlock reported for a memory without sealing")
+	}
+}
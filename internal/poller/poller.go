@@ -0,0 +1,255 @@
+// internal/poller/poller.go
+package poller
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"MMA2.0/internal/authority"
+	"MMA2.0/internal/memorycore"
+	"MMA2.0/internal/transport/modbus"
+)
+
+// The poller is a Modbus TCP master: it reads ranges from a downstream
+// device on a schedule and writes them into one local memory.
+//
+// Poller writes are configured by the operator, not requested by a
+// remote producer: they bypass ingest_policy and go straight to
+// memorycore. Bit writes are still reported to state sealing so a
+// polled device can drive the RUN flag.
+
+var errLocalWrite = errors.New("local write")
+
+// Defaults.
+const (
+	DefaultInterval = time.Second
+	DefaultTimeout  = time.Second
+)
+
+// Health status codes (status register word 0).
+const (
+	StatusNeverPolled = uint16(0)
+	StatusOK          = uint16(1)
+	StatusCommError   = uint16(2) // dial / timeout / malformed response
+	StatusException   = uint16(3) // device answered with a Modbus exception
+)
+
+// StatusWords is the size of the status block:
+//
+//	+0 status code (StatusOK, ...)
+//	+1 consecutive failed cycles (saturating)
+//	+2 last exception code (0 = none)
+const StatusWords = 3
+
+// Read is one polled range.
+type Read struct {
+	Function     uint8 // 1,2,3,4
+	Address      uint16
+	Count        uint16
+	LocalAddress uint16
+}
+
+// Area returns the local memory area for the read's function code.
+func (r Read) Area() memorycore.Area {
+	switch r.Function {
+	case 1:
+		return memorycore.AreaCoils
+	case 2:
+		return memorycore.AreaDiscreteInputs
+	case 3:
+		return memorycore.AreaHoldingRegs
+	case 4:
+		return memorycore.AreaInputRegs
+	default:
+		return memorycore.AreaInvalid
+	}
+}
+
+// Status locates the health register block in the target memory.
+type Status struct {
+	Area    memorycore.Area // holding or input registers
+	Address uint16
+}
+
+// Options configures one polled device.
+type Options struct {
+	ID      string
+	Address string // host:port
+	UnitID  uint8  // remote unit id
+
+	Interval time.Duration
+	Timeout  time.Duration
+	Retries  int // extra attempts per read within one cycle
+
+	Target memorycore.MemoryID
+	Reads  []Read
+	Status *Status
+}
+
+// Poller polls one device.
+type Poller struct {
+	opts Options
+	mem  *memorycore.Memory
+	auth *authority.Authority
+
+	client *modbus.Client
+
+	failures uint16
+
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// New creates a poller for an existing target memory.
+func New(opts Options, store *memorycore.Store, auth *authority.Authority) (*Poller, error) {
+	mem, err := store.MustGet(opts.Target)
+	if err != nil {
+		return nil, fmt.Errorf("poller %s: target (port=%d unit=%d): %w", opts.ID, opts.Target.Port, opts.Target.UnitID, err)
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.Retries < 0 {
+		opts.Retries = 0
+	}
+	return &Poller{
+		opts: opts,
+		mem:  mem,
+		auth: auth,
+		done: make(chan struct{}),
+	}, nil
+}
+
+// Stop ends Run.
+func (p *Poller) Stop() {
+	p.stopOnce.Do(func() { close(p.done) })
+}
+
+// Run polls every Interval until Stop is called.
+// A slow cycle delays the next one; cycles never overlap.
+func (p *Poller) Run() {
+	t := time.NewTicker(p.opts.Interval)
+	defer t.Stop()
+	defer p.disconnect()
+
+	for {
+		p.cycle()
+
+		select {
+		case <-p.done:
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// cycle performs every read once (with retries) and updates health.
+// A cycle fails on the first read that exhausts its retries; ranges
+// already read in that cycle keep their new values.
+func (p *Poller) cycle() {
+	for _, rd := range p.opts.Reads {
+		var err error
+		for attempt := 0; attempt <= p.opts.Retries; attempt++ {
+			if err = p.readOnce(rd); err == nil {
+				break
+			}
+
+			// Exceptions are answers and local writes are deterministic:
+			// retrying will not change them.
+			var ex *modbus.ExceptionError
+			if errors.As(err, &ex) || errors.Is(err, errLocalWrite) {
+				break
+			}
+			p.disconnect()
+		}
+
+		if err != nil {
+			p.fail(rd, err)
+			return
+		}
+	}
+
+	p.failures = 0
+	p.setStatus(StatusOK, 0)
+}
+
+func (p *Poller) readOnce(rd Read) error {
+	if p.client == nil {
+		c, err := modbus.Dial(p.opts.Address, p.opts.Timeout)
+		if err != nil {
+			return err
+		}
+		p.client = c
+	}
+
+	area := rd.Area()
+
+	var data []byte
+	var err error
+	if area.IsBitArea() {
+		data, err = p.client.ReadBits(p.opts.UnitID, rd.Function, rd.Address, rd.Count)
+	} else {
+		data, err = p.client.ReadRegs(p.opts.UnitID, rd.Function, rd.Address, rd.Count)
+	}
+	if err != nil {
+		return err
+	}
+
+	if area.IsBitArea() {
+		if err := p.mem.WriteBits(area, rd.LocalAddress, rd.Count, data); err != nil {
+			return fmt.Errorf("%w: %w", errLocalWrite, err)
+		}
+		p.auth.Sealing().ObserveIngestWrite(p.opts.Target, area, rd.LocalAddress, rd.Count, data)
+		return nil
+	}
+
+	if err := p.mem.WriteRegs(area, rd.LocalAddress, rd.Count, data); err != nil {
+		return fmt.Errorf("%w: %w", errLocalWrite, err)
+	}
+	return nil
+}
+
+func (p *Poller) fail(rd Read, err error) {
+	if p.failures < 0xFFFF {
+		p.failures++
+	}
+
+	var ex *modbus.ExceptionError
+	if errors.As(err, &ex) {
+		p.setStatus(StatusException, uint16(ex.Code))
+	} else {
+		p.setStatus(StatusCommError, 0)
+	}
+
+	log.Printf("poller %s: fc%d %d+%d from %s failed: %v [consecutive=%d]",
+		p.opts.ID, rd.Function, rd.Address, rd.Count, p.opts.Address, err, p.failures)
+}
+
+func (p *Poller) setStatus(code, exception uint16) {
+	if p.opts.Status == nil {
+		return
+	}
+
+	var buf [StatusWords * 2]byte
+	binary.BigEndian.PutUint16(buf[0:2], code)
+	binary.BigEndian.PutUint16(buf[2:4], p.failures)
+	binary.BigEndian.PutUint16(buf[4:6], exception)
+
+	if err := p.mem.WriteRegs(p.opts.Status.Area, p.opts.Status.Address, StatusWords, buf[:]); err != nil {
+		log.Printf("poller %s: status write failed: %v", p.opts.ID, err)
+	}
+}
+
+func (p *Poller) disconnect() {
+	if p.client != nil {
+		_ = p.client.Close()
+		p.client = nil
+	}
+}
@@ -0,0 +1,326 @@
+// internal/poller/poller_test.go
+package poller
+
+import (
+	"encoding/binary"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"MMA2.0/internal/authority"
+	"MMA2.0/internal/memorycore"
+	"MMA2.0/internal/transport/modbus"
+)
+
+// testDevice is a downstream Modbus TCP device: modbus.HandleConn on a
+// loopback listener, serving one memory (unit 1) on the listener port.
+type testDevice struct {
+	ln  net.Listener
+	mem *memorycore.Memory
+
+	mu      sync.Mutex
+	conns   []net.Conn
+	accepts int
+	drop    int // next connections to close right after accept
+}
+
+func newTestDevice(t *testing.T, allowFC []uint8) *testDevice {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	mid := memorycore.MemoryID{Port: uint16(ln.Addr().(*net.TCPAddr).Port), UnitID: 1}
+	d := &testDevice{ln: ln, mem: newMemory(t)}
+
+	store := memorycore.NewStore()
+	if err := store.Add(mid, d.mem); err != nil {
+		t.Fatal(err)
+	}
+	rule, err := authority.NewRule("test", []string{"127.0.0.1"}, allowFC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := authority.New()
+	auth.SetMemoryPolicy(mid, &authority.MemoryPolicy{Rules: []*authority.Rule{rule}})
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			d.mu.Lock()
+			d.accepts++
+			drop := d.drop > 0
+			if drop {
+				d.drop--
+			} else {
+				d.conns = append(d.conns, conn)
+			}
+			d.mu.Unlock()
+
+			if drop {
+				conn.Close()
+				continue
+			}
+			go modbus.HandleConn(conn, store, auth)
+		}
+	}()
+	t.Cleanup(d.closeConns)
+
+	return d
+}
+
+func (d *testDevice) addr() string { return d.ln.Addr().String() }
+
+func (d *testDevice) setDrop(n int) {
+	d.mu.Lock()
+	d.drop = n
+	d.mu.Unlock()
+}
+
+func (d *testDevice) acceptCount() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.accepts
+}
+
+// closeConns closes every served connection (server-side close).
+func (d *testDevice) closeConns() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, c := range d.conns {
+		c.Close()
+	}
+	d.conns = nil
+}
+
+func newMemory(t *testing.T) *memorycore.Memory {
+	t.Helper()
+	mem, err := memorycore.NewMemory(memorycore.MemoryLayouts{
+		Coils:          &memorycore.AreaLayout{Start: 0, Size: 32},
+		DiscreteInputs: &memorycore.AreaLayout{Start: 0, Size: 32},
+		HoldingRegs:    &memorycore.AreaLayout{Start: 0, Size: 32},
+		InputRegs:      &memorycore.AreaLayout{Start: 0, Size: 32},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mem
+}
+
+// newPoller creates a poller targeting a fresh local memory.
+func newPoller(t *testing.T, opts Options) (*Poller, *memorycore.Memory) {
+	t.Helper()
+
+	mem := newMemory(t)
+	store := memorycore.NewStore()
+	opts.Target = memorycore.MemoryID{Port: 502, UnitID: 1}
+	if err := store.Add(opts.Target, mem); err != nil {
+		t.Fatal(err)
+	}
+	if opts.ID == "" {
+		opts.ID = "test"
+	}
+	if opts.UnitID == 0 {
+		opts.UnitID = 1
+	}
+	if opts.Status == nil {
+		opts.Status = &Status{Area: memorycore.AreaInputRegs, Address: 20}
+	}
+
+	p, err := New(opts, store, authority.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(p.disconnect)
+	return p, mem
+}
+
+func regs(t *testing.T, mem *memorycore.Memory, area memorycore.Area, address, count uint16) []uint16 {
+	t.Helper()
+	buf := make([]byte, 2*count)
+	if err := mem.ReadRegs(area, address, count, buf); err != nil {
+		t.Fatal(err)
+	}
+	out := make([]uint16, count)
+	for i := range out {
+		out[i] = binary.BigEndian.Uint16(buf[2*i:])
+	}
+	return out
+}
+
+func putRegs(t *testing.T, mem *memorycore.Memory, area memorycore.Area, address uint16, values ...uint16) {
+	t.Helper()
+	buf := make([]byte, 0, 2*len(values))
+	for _, v := range values {
+		buf = binary.BigEndian.AppendUint16(buf, v)
+	}
+	if err := mem.WriteRegs(area, address, uint16(len(values)), buf); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func bits(t *testing.T, mem *memorycore.Memory, area memorycore.Area, address, count uint16) []byte {
+	t.Helper()
+	buf := make([]byte, (count+7)/8)
+	if err := mem.ReadBits(area, address, count, buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+func wantStatus(t *testing.T, mem *memorycore.Memory, code, failures, exception uint16) {
+	t.Helper()
+	got := regs(t, mem, memorycore.AreaInputRegs, 20, StatusWords)
+	if got[0] != code || got[1] != failures || got[2] != exception {
+		t.Fatalf("status = %v, want [%d %d %d]", got, code, failures, exception)
+	}
+}
+
+func TestCycleReadsIntoTarget(t *testing.T) {
+	d := newTestDevice(t, []uint8{1, 2, 3, 4})
+
+	if err := d.mem.WriteBits(memorycore.AreaCoils, 0, 10, []byte{0xA5, 0x02}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.mem.WriteBits(memorycore.AreaDiscreteInputs, 4, 3, []byte{0x05}); err != nil {
+		t.Fatal(err)
+	}
+	putRegs(t, d.mem, memorycore.AreaHoldingRegs, 1, 0x1111, 0x2222, 0x3333)
+	putRegs(t, d.mem, memorycore.AreaInputRegs, 7, 0xBEEF)
+
+	p, mem := newPoller(t, Options{
+		Address: d.addr(),
+		Reads: []Read{
+			{Function: 1, Address: 0, Count: 10, LocalAddress: 16},
+			{Function: 2, Address: 4, Count: 3, LocalAddress: 0},
+			{Function: 3, Address: 1, Count: 3, LocalAddress: 10},
+			{Function: 4, Address: 7, Count: 1, LocalAddress: 0},
+		},
+	})
+
+	p.cycle()
+
+	if got := bits(t, mem, memorycore.AreaCoils, 16, 10); got[0] != 0xA5 || got[1] != 0x02 {
+		t.Errorf("coils[16:26] = % x, want a5 02", got)
+	}
+	if got := bits(t, mem, memorycore.AreaDiscreteInputs, 0, 3); got[0] != 0x05 {
+		t.Errorf("discrete inputs[0:3] = % x, want 05", got)
+	}
+	if got := regs(t, mem, memorycore.AreaHoldingRegs, 10, 3); got[0] != 0x1111 || got[1] != 0x2222 || got[2] != 0x3333 {
+		t.Errorf("holding[10:13] = %x", got)
+	}
+	if got := regs(t, mem, memorycore.AreaInputRegs, 0, 1); got[0] != 0xBEEF {
+		t.Errorf("input[0] = %x, want beef", got[0])
+	}
+	wantStatus(t, mem, StatusOK, 0, 0)
+}
+
+func TestCycleException(t *testing.T) {
+	d := newTestDevice(t, []uint8{3})
+
+	// Out of range on the device: illegal data address (0x02).
+	p, mem := newPoller(t, Options{
+		Address: d.addr(),
+		Retries: 2,
+		Reads:   []Read{{Function: 3, Address: 30, Count: 5}},
+	})
+	p.cycle()
+	wantStatus(t, mem, StatusException, 1, 0x02)
+
+	// Exceptions are answers: no retry, no reconnect.
+	if n := d.acceptCount(); n != 1 {
+		t.Errorf("connections = %d, want 1", n)
+	}
+
+	// FC not allowed by the device policy: illegal function (0x01).
+	p.opts.Reads = []Read{{Function: 4, Address: 0, Count: 1}}
+	p.cycle()
+	wantStatus(t, mem, StatusException, 2, 0x01)
+}
+
+func TestCycleCommError(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	p, mem := newPoller(t, Options{
+		Address: addr,
+		Timeout: 200 * time.Millisecond,
+		Reads:   []Read{{Function: 3, Address: 0, Count: 1}},
+	})
+
+	p.cycle()
+	wantStatus(t, mem, StatusCommError, 1, 0)
+	p.cycle()
+	wantStatus(t, mem, StatusCommError, 2, 0)
+}
+
+func TestCycleRetries(t *testing.T) {
+	d := newTestDevice(t, []uint8{3})
+	putRegs(t, d.mem, memorycore.AreaHoldingRegs, 0, 42)
+
+	// One dropped connection is absorbed by one retry.
+	d.setDrop(1)
+	p, mem := newPoller(t, Options{
+		Address: d.addr(),
+		Retries: 1,
+		Reads:   []Read{{Function: 3, Address: 0, Count: 1}},
+	})
+	p.cycle()
+	wantStatus(t, mem, StatusOK, 0, 0)
+	if got := regs(t, mem, memorycore.AreaHoldingRegs, 0, 1); got[0] != 42 {
+		t.Errorf("holding[0] = %d, want 42", got[0])
+	}
+	if n := d.acceptCount(); n != 2 {
+		t.Errorf("connections = %d, want 2", n)
+	}
+
+	// Two dropped connections exhaust it.
+	p.disconnect()
+	d.setDrop(2)
+	p.cycle()
+	wantStatus(t, mem, StatusCommError, 1, 0)
+	if n := d.acceptCount(); n != 4 {
+		t.Errorf("connections = %d, want 4", n)
+	}
+}
+
+func TestCycleReconnect(t *testing.T) {
+	d := newTestDevice(t, []uint8{3})
+	putRegs(t, d.mem, memorycore.AreaHoldingRegs, 0, 1)
+
+	p, mem := newPoller(t, Options{
+		Address: d.addr(),
+		Reads:   []Read{{Function: 3, Address: 0, Count: 1}},
+	})
+	p.cycle()
+	wantStatus(t, mem, StatusOK, 0, 0)
+
+	// The device closes the connection: the next cycle fails on the
+	// stale connection, the one after reconnects.
+	d.closeConns()
+	putRegs(t, d.mem, memorycore.AreaHoldingRegs, 0, 2)
+
+	p.cycle()
+	wantStatus(t, mem, StatusCommError, 1, 0)
+
+	p.cycle()
+	wantStatus(t, mem, StatusOK, 0, 0)
+	if got := regs(t, mem, memorycore.AreaHoldingRegs, 0, 1); got[0] != 2 {
+		t.Errorf("holding[0] = %d, want 2", got[0])
+	}
+	if n := d.acceptCount(); n != 2 {
+		t.Errorf("connections = %d, want 2", n)
+	}
+}
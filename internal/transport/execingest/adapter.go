@@ -0,0 +1,356 @@
+// internal/transport/execingest/adapter.go
+package execingest
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/netip"
+	"os"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"MMA2.0/internal/authority"
+	"MMA2.0/internal/ingest"
+	"MMA2.0/internal/memorycore"
+)
+
+// Exec adapters are write-only ingest transports backed by child processes.
+//
+// The child writes one JSON write request per line on stdout
+// (ingest.WriteRequest, the REST schema). Every line that fails is
+// reported back on the child's stdin as one JSON line:
+//
+//	{"line":12,"error":{"code":"out_of_bounds","message":"out of bounds"}}
+//
+// Accepted lines produce no output. stderr is forwarded to the log.
+// A child that exits is restarted with exponential backoff.
+
+// Source is the ingest policy source of every exec adapter write.
+var Source = netip.MustParseAddr("127.0.0.1")
+
+// Defaults.
+const (
+	DefaultMinBackoff = time.Second
+	DefaultMaxBackoff = time.Minute
+
+	// MaxLineBytes caps one stdout line.
+	MaxLineBytes = 1 << 20
+)
+
+// Options configures one adapter.
+type Options struct {
+	ID      string
+	Command []string // argv, Command[0] is the executable
+	Dir     string
+	Env     []string // appended to the parent environment
+
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// Stats are the line counters of one adapter.
+type Stats struct {
+	Lines    uint64
+	Applied  uint64
+	Rejected uint64
+	Restarts uint64
+}
+
+// Adapter supervises one driver process.
+type Adapter struct {
+	opts  Options
+	store *memorycore.Store
+	auth  *authority.Authority
+
+	done     chan struct{}
+	stopOnce sync.Once
+
+	lines    atomic.Uint64
+	applied  atomic.Uint64
+	rejected atomic.Uint64
+	restarts atomic.Uint64
+}
+
+// NewAdapter creates an adapter. Zero backoff values select defaults.
+func NewAdapter(opts Options, store *memorycore.Store, auth *authority.Authority) *Adapter {
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = DefaultMinBackoff
+	}
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = DefaultMaxBackoff
+	}
+	return &Adapter{
+		opts:  opts,
+		store: store,
+		auth:  auth,
+		done:  make(chan struct{}),
+	}
+}
+
+// Stats returns the adapter counters.
+func (a *Adapter) Stats() Stats {
+	return Stats{
+		Lines:    a.lines.Load(),
+		Applied:  a.applied.Load(),
+		Rejected: a.rejected.Load(),
+		Restarts: a.restarts.Load(),
+	}
+}
+
+// Stop ends Run and kills the running child.
+func (a *Adapter) Stop() {
+	a.stopOnce.Do(func() { close(a.done) })
+}
+
+// Run starts the child and restarts it until Stop is called.
+// Backoff doubles on every exit and resets once a child has run
+// for at least MaxBackoff.
+func (a *Adapter) Run() {
+	backoff := a.opts.MinBackoff
+
+	for {
+		started := time.Now()
+		err := a.runOnce()
+
+		select {
+		case <-a.done:
+			return
+		default:
+		}
+
+		if time.Since(started) >= a.opts.MaxBackoff {
+			backoff = a.opts.MinBackoff
+		}
+		log.Printf("exec %s: %s (restart in %s)", a.opts.ID, describeExit(err), backoff)
+
+		select {
+		case <-a.done:
+			return
+		case <-time.After(backoff):
+		}
+
+		a.restarts.Add(1)
+		backoff *= 2
+		if backoff > a.opts.MaxBackoff {
+			backoff = a.opts.MaxBackoff
+		}
+	}
+}
+
+// runOnce runs one child to completion.
+func (a *Adapter) runOnce() error {
+	cmd := exec.Command(a.opts.Command[0], a.opts.Command[1:]...)
+	cmd.Dir = a.opts.Dir
+	cmd.Env = append(os.Environ(), a.opts.Env...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	log.Printf("exec %s: started pid %d", a.opts.ID, cmd.Process.Pid)
+
+	exited := make(chan struct{})
+	go func() {
+		select {
+		case <-a.done:
+			_ = cmd.Process.Kill()
+		case <-exited:
+		}
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		a.forwardStderr(stderr)
+	}()
+	go func() {
+		defer wg.Done()
+		a.serve(stdout, stdin)
+	}()
+
+	wg.Wait()
+	err = cmd.Wait()
+	close(exited)
+	return err
+}
+
+// reportQueue bounds pending stdin reports. A child that never reads
+// its stdin must not stall stdout processing: excess reports are dropped.
+const reportQueue = 256
+
+// serve applies stdout lines and reports failures on stdin.
+func (a *Adapter) serve(stdout io.Reader, stdin io.WriteCloser) {
+	reports := make(chan lineReport, reportQueue)
+	reporterDone := make(chan struct{})
+
+	go func() {
+		defer close(reporterDone)
+		defer stdin.Close()
+
+		enc := json.NewEncoder(stdin)
+		for r := range reports {
+			// A child that closed its stdin still gets its lines applied.
+			if err := enc.Encode(r); err != nil {
+				for range reports {
+				}
+				return
+			}
+		}
+	}()
+
+	defer func() {
+		close(reports)
+		<-reporterDone
+	}()
+
+	br := bufio.NewReaderSize(stdout, 64*1024)
+
+	for n := 1; ; n++ {
+		line, err := readLine(br, MaxLineBytes)
+		if err == io.EOF {
+			return
+		}
+		if err != nil && !errors.Is(err, ErrLineTooLong) {
+			log.Printf("exec %s: stdout: %v", a.opts.ID, err)
+			// Drain so the child is not blocked on a full pipe.
+			_, _ = io.Copy(io.Discard, stdout)
+			return
+		}
+
+		a.lines.Add(1)
+
+		if err == nil && len(line) == 0 {
+			continue
+		}
+		if err == nil {
+			err = a.apply(line)
+		}
+		if err == nil {
+			a.applied.Add(1)
+			continue
+		}
+
+		r := a.rejected.Add(1)
+		log.Printf("exec %s: line %d rejected: %v [rejected=%d]", a.opts.ID, n, err, r)
+
+		select {
+		case reports <- lineError(n, err):
+		default:
+			log.Printf("exec %s: stdin report queue full, dropping report for line %d", a.opts.ID, n)
+		}
+	}
+}
+
+// ErrLineTooLong rejects a stdout line above MaxLineBytes.
+// The line is skipped up to its newline; later lines are still applied.
+var ErrLineTooLong = fmt.Errorf("%w: line exceeds %d bytes", ingest.ErrBadRequest, MaxLineBytes)
+
+// readLine reads one line without its end-of-line marker.
+// A line longer than max is consumed in full and reported as ErrLineTooLong.
+func readLine(r *bufio.Reader, max int) ([]byte, error) {
+	var line []byte
+	tooLong := false
+
+	for {
+		chunk, more, err := r.ReadLine()
+		if err != nil {
+			if err == io.EOF && (tooLong || line != nil) {
+				break
+			}
+			return nil, err
+		}
+
+		if !tooLong && len(line)+len(chunk) > max {
+			tooLong, line = true, nil
+		}
+		if !tooLong {
+			line = append(line, chunk...)
+		}
+		if !more {
+			break
+		}
+	}
+
+	if tooLong {
+		return nil, ErrLineTooLong
+	}
+	return line, nil
+}
+
+// apply decodes and writes one JSON line.
+func (a *Adapter) apply(line []byte) error {
+	req, err := ingest.DecodeWriteRequest(line)
+	if err != nil {
+		return err
+	}
+	mid, writes, err := req.Resolve()
+	if err != nil {
+		return err
+	}
+	return ingest.Apply(a.store, a.auth, ingest.Source{IP: Source}, mid, writes)
+}
+
+func (a *Adapter) forwardStderr(r io.Reader) {
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		log.Printf("exec %s: stderr: %s", a.opts.ID, sc.Text())
+	}
+
+	if err := sc.Err(); err != nil {
+		log.Printf("exec %s: stderr: %v (discarding further stderr)", a.opts.ID, err)
+		// Keep draining: a child blocked on stderr would stall stdout too.
+		_, _ = io.Copy(io.Discard, r)
+	}
+}
+
+type lineReport struct {
+	Line  int         `json:"line"`
+	Error errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func lineError(n int, err error) lineReport {
+	return lineReport{
+		Line:  n,
+		Error: errorDetail{Code: ingest.CodeFor(err), Message: err.Error()},
+	}
+}
+
+// describeExit formats a child's exit for logging.
+func describeExit(err error) string {
+	var ee *exec.ExitError
+	switch {
+	case err == nil:
+		return "exited with code 0"
+	case errors.As(err, &ee):
+		if ee.Exited() {
+			return fmt.Sprintf("exited with code %d", ee.ExitCode())
+		}
+		return fmt.Sprintf("terminated: %v", ee)
+	default:
+		return fmt.Sprintf("failed: %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+// internal/transport/modbus/client.go
+package modbus
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Client is a minimal Modbus TCP master for reads (FC 1,2,3,4) and
+// multiple writes (FC 15,16).
+// It is not safe for concurrent use; one request is in flight at a time.
+type Client struct {
+	conn    net.Conn
+	r       *bufio.Reader
+	timeout time.Duration
+	tid     uint16
+}
+
+// ExceptionError is a Modbus exception response from the remote device.
+type ExceptionError struct {
+	FunctionCode uint8
+	Code         uint8
+}
+
+func (e *ExceptionError) Error() string {
+	return fmt.Sprintf("modbus exception 0x%02X (fc %d)", e.Code, e.FunctionCode)
+}
+
+// ErrBadResponse reports a response that does not match its request.
+var ErrBadResponse = errors.New("modbus: bad response")
+
+// Read quantity limits (Modbus application protocol).
+const (
+	MaxReadBits = 2000
+	MaxReadRegs = 125
+
+	MaxWriteBits = 1968
+	MaxWriteRegs = 123
+)
+
+// Dial connects to a Modbus TCP server.
+// timeout bounds the dial and every request/response exchange.
+func Dial(addr string, timeout time.Duration) (*Client, error) {
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(conn, timeout), nil
+}
+
+// NewClient wraps an established connection.
+func NewClient(conn net.Conn, timeout time.Duration) *Client {
+	return &Client{conn: conn, r: bufio.NewReader(conn), timeout: timeout}
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// ReadBits reads coils (FC1) or discrete inputs (FC2).
+// The result is packed LSB-first, ceil(qty/8) bytes.
+func (c *Client) ReadBits(unitID uint8, fc uint8, addr, qty uint16) ([]byte, error) {
+	if fc != 1 && fc != 2 {
+		return nil, fmt.Errorf("modbus: fc %d is not a bit read", fc)
+	}
+	if qty == 0 || qty > MaxReadBits {
+		return nil, fmt.Errorf("modbus: invalid bit quantity %d", qty)
+	}
+	return c.read(unitID, fc, addr, qty, bytesForBits(qty))
+}
+
+// ReadRegs reads holding (FC3) or input (FC4) registers.
+// The result is big-endian words, qty*2 bytes.
+func (c *Client) ReadRegs(unitID uint8, fc uint8, addr, qty uint16) ([]byte, error) {
+	if fc != 3 && fc != 4 {
+		return nil, fmt.Errorf("modbus: fc %d is not a register read", fc)
+	}
+	if qty == 0 || qty > MaxReadRegs {
+		return nil, fmt.Errorf("modbus: invalid register quantity %d", qty)
+	}
+	return c.read(unitID, fc, addr, qty, int(qty)*2)
+}
+
+func (c *Client) read(unitID, fc uint8, addr, qty uint16, want int) ([]byte, error) {
+	c.tid++
+
+	pdu := make([]byte, 5)
+	pdu[0] = fc
+	binary.BigEndian.PutUint16(pdu[1:3], addr)
+	binary.BigEndian.PutUint16(pdu[3:5], qty)
+
+	resp, err := c.Do(c.tid, unitID, pdu)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp[0] == fc|0x80 {
+		if len(resp) != 2 {
+			return nil, fmt.Errorf("%w: exception length", ErrBadResponse)
+		}
+		return nil, &ExceptionError{FunctionCode: fc, Code: resp[1]}
+	}
+	if resp[0] != fc {
+		return nil, fmt.Errorf("%w: function code %d", ErrBadResponse, resp[0])
+	}
+
+	if len(resp) < 2 || int(resp[1]) != want || len(resp) != 2+want {
+		return nil, fmt.Errorf("%w: byte count", ErrBadResponse)
+	}
+
+	return resp[2:], nil
+}
+
+// WriteBits writes coils (FC15). src is packed LSB-first, ceil(qty/8) bytes.
+func (c *Client) WriteBits(unitID uint8, addr, qty uint16, src []byte) error {
+	if qty == 0 || qty > MaxWriteBits {
+		return fmt.Errorf("modbus: invalid bit quantity %d", qty)
+	}
+	if len(src) != bytesForBits(qty) {
+		return fmt.Errorf("modbus: bit data length %d for quantity %d", len(src), qty)
+	}
+	return c.write(15, unitID, addr, qty, src)
+}
+
+// WriteRegs writes holding registers (FC16). src is big-endian words, qty*2 bytes.
+func (c *Client) WriteRegs(unitID uint8, addr, qty uint16, src []byte) error {
+	if qty == 0 || qty > MaxWriteRegs {
+		return fmt.Errorf("modbus: invalid register quantity %d", qty)
+	}
+	if len(src) != int(qty)*2 {
+		return fmt.Errorf("modbus: register data length %d for quantity %d", len(src), qty)
+	}
+	return c.write(16, unitID, addr, qty, src)
+}
+
+func (c *Client) write(fc, unitID uint8, addr, qty uint16, src []byte) error {
+	c.tid++
+
+	pdu := make([]byte, 6+len(src))
+	pdu[0] = fc
+	binary.BigEndian.PutUint16(pdu[1:3], addr)
+	binary.BigEndian.PutUint16(pdu[3:5], qty)
+	pdu[5] = uint8(len(src))
+	copy(pdu[6:], src)
+
+	resp, err := c.Do(c.tid, unitID, pdu)
+	if err != nil {
+		return err
+	}
+
+	if resp[0] == fc|0x80 {
+		if len(resp) != 2 {
+			return fmt.Errorf("%w: exception length", ErrBadResponse)
+		}
+		return &ExceptionError{FunctionCode: fc, Code: resp[1]}
+	}
+	if resp[0] != fc {
+		return fmt.Errorf("%w: function code %d", ErrBadResponse, resp[0])
+	}
+
+	if len(resp) != 5 ||
+		binary.BigEndian.Uint16(resp[1:3]) != addr ||
+		binary.BigEndian.Uint16(resp[3:5]) != qty {
+		return fmt.Errorf("%w: write echo", ErrBadResponse)
+	}
+
+	return nil
+}
+
+// Do sends one request PDU (function code + data) with the given
+// transaction ID and returns the response PDU, unparsed.
+// Exception responses are returned as PDUs, not as errors.
+func (c *Client) Do(tid uint16, unitID uint8, pdu []byte) ([]byte, error) {
+	if len(pdu) == 0 || len(pdu) > 253 {
+		return nil, fmt.Errorf("modbus: invalid PDU length %d", len(pdu))
+	}
+
+	frame := make([]byte, 7+len(pdu))
+	binary.BigEndian.PutUint16(frame[0:2], tid)
+	binary.BigEndian.PutUint16(frame[4:6], uint16(len(pdu)+1))
+	frame[6] = unitID
+	copy(frame[7:], pdu)
+
+	if err := c.conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+		return nil, err
+	}
+	if _, err := c.conn.Write(frame); err != nil {
+		return nil, err
+	}
+
+	// Responses share the request frame layout (MBAP + PDU).
+	resp, err := ReadRequest(c.r, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.TransactionID != tid || resp.ProtocolID != 0 || resp.UnitID != unitID {
+		return nil, fmt.Errorf("%w: header mismatch (tid %d, unit %d)", ErrBadResponse, resp.TransactionID, resp.UnitID)
+	}
+
+	return append([]byte{resp.FunctionCode}, resp.Payload...), nil
+}
@@ -0,0 +1,134 @@
+// internal/transport/modbus/device_identification.go
+package modbus
+
+import "MMA2.0/internal/memorycore"
+
+// MEIReadDeviceID is the MEI type of FC43 read device identification.
+const MEIReadDeviceID = uint8(0x0E)
+
+// Read device ID codes.
+const (
+	readDevIDBasic      = uint8(1)
+	readDevIDRegular    = uint8(2)
+	readDevIDExtended   = uint8(3)
+	readDevIDIndividual = uint8(4)
+)
+
+// conformityIndividual is OR-ed into the conformity level:
+// individual access (read code 4) is always supported.
+const conformityIndividual = uint8(0x80)
+
+// maxPDU is the largest Modbus PDU (function code + data).
+const maxPDU = 253
+
+// deviceIDHeaderLen: FC(1) MEI(1) ReadCode(1) Conformity(1)
+// MoreFollows(1) NextObjectID(1) NumberOfObjects(1)
+const deviceIDHeaderLen = 7
+
+// handleReadDeviceID serves FC43 / MEI 0x0E from the memory's identity.
+//
+// Stream access (codes 1-3) returns the objects of the category from
+// ObjectID on, as many as fit in one PDU; "more follows" and the next
+// object ID tell the client where to continue. A request above the
+// conformity level is answered at the conformity level.
+func handleReadDeviceID(store *memorycore.Store, req *Request) []byte {
+	if len(req.Payload) > 0 && req.Payload[0] != MEIReadDeviceID {
+		// Illegal Function (other MEI types are not supported)
+		return BuildExceptionPDU(req.FunctionCode, 0x01)
+	}
+
+	decoded, err := DecodeReadDeviceID(req.Payload)
+	if err != nil || decoded.ReadCode < readDevIDBasic || decoded.ReadCode > readDevIDIndividual {
+		// Illegal Data Value
+		return BuildExceptionPDU(req.FunctionCode, 0x03)
+	}
+
+	mem, ok := resolveMemory(store, req)
+	if !ok {
+		// Illegal Data Address
+		return BuildExceptionPDU(req.FunctionCode, 0x02)
+	}
+
+	ident := mem.DeviceIdentity()
+	if ident == nil {
+		// Illegal Function (no identification configured)
+		return BuildExceptionPDU(req.FunctionCode, 0x01)
+	}
+
+	level := conformityLevel(ident)
+
+	if decoded.ReadCode == readDevIDIndividual {
+		v, ok := ident.Objects[decoded.ObjectID]
+		if !ok {
+			// Illegal Data Address
+			return BuildExceptionPDU(req.FunctionCode, 0x02)
+		}
+		out := deviceIDHeader(decoded.ReadCode, level)
+		out = append(out, decoded.ObjectID, uint8(len(v)))
+		out = append(out, v...)
+		out[6] = 1
+		return out
+	}
+
+	code := min(decoded.ReadCode, level&^conformityIndividual)
+	last := categoryLast(code)
+
+	// An object outside the category (or absent) restarts the stream.
+	start := decoded.ObjectID
+	if _, ok := ident.Objects[start]; !ok || start > last {
+		start = 0
+	}
+
+	out := deviceIDHeader(code, level)
+	for oid := int(start); oid <= int(last); oid++ {
+		v, ok := ident.Objects[uint8(oid)]
+		if !ok {
+			continue
+		}
+		if len(out)+2+len(v) > maxPDU {
+			out[4] = 0xFF // more follows
+			out[5] = uint8(oid)
+			break
+		}
+		out = append(out, uint8(oid), uint8(len(v)))
+		out = append(out, v...)
+		out[6]++
+	}
+
+	return out
+}
+
+func deviceIDHeader(code, level uint8) []byte {
+	out := make([]byte, deviceIDHeaderLen, maxPDU)
+	out[0] = 43
+	out[1] = MEIReadDeviceID
+	out[2] = code
+	out[3] = level
+	return out
+}
+
+// conformityLevel derives the level from the highest configured object.
+func conformityLevel(ident *memorycore.DeviceIdentity) uint8 {
+	level := readDevIDBasic
+	for oid := range ident.Objects {
+		switch {
+		case oid >= memorycore.DeviceObjectFirstPrivate:
+			level = readDevIDExtended
+		case oid > memorycore.DeviceObjectRevision && level < readDevIDRegular:
+			level = readDevIDRegular
+		}
+	}
+	return level | conformityIndividual
+}
+
+// categoryLast is the last object ID of a stream category.
+func categoryLast(code uint8) uint8 {
+	switch code {
+	case readDevIDBasic:
+		return memorycore.DeviceObjectRevision
+	case readDevIDRegular:
+		return memorycore.DeviceObjectFirstPrivate - 1
+	default:
+		return 0xFF
+	}
+}
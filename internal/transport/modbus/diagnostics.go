@@ -0,0 +1,325 @@
+// internal/transport/modbus/diagnostics.go
+package modbus
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"MMA2.0/internal/memorycore"
+)
+
+// Diagnostics keeps the serial-line style diagnostic counters and the
+// communication event log of every local memory, and serves them with
+// FC8 (diagnostics), FC11 (comm event counter) and FC12 (comm event log).
+//
+// Counting (per MemoryID, 16-bit, wrapping):
+//   - bus messages: every request received for the unit
+//   - bus exceptions: every exception response (including authority denials)
+//   - server messages: every request that passed authority
+//   - server busy: Device Busy (0x06) responses, i.e. state sealing
+//   - comm events (FC11): successful responses except FC11 itself
+//
+// Bus communication errors, no-response, NAK and character overrun do not
+// occur on Modbus TCP; they are served and always 0.
+//
+// Gateway-routed unit IDs are not counted: the backend answers FC8/11/12.
+type Diagnostics struct {
+	mu    sync.Mutex
+	units map[memorycore.MemoryID]*unitDiagnostics
+}
+
+// DiagnosticCounters is a snapshot of one memory's counters.
+type DiagnosticCounters struct {
+	BusMessages      uint16
+	BusCommErrors    uint16
+	BusExceptions    uint16
+	ServerMessages   uint16
+	ServerNoResponse uint16
+	ServerNAK        uint16
+	ServerBusy       uint16
+	CharOverruns     uint16
+	CommEvents       uint16
+}
+
+// eventLogSize is the FC12 event log depth (Modbus application protocol).
+const eventLogSize = 64
+
+// Comm event log entries (FC12).
+const (
+	eventReceive = byte(0x80) // server receive event (TCP: no error bits)
+	eventSend    = byte(0x40) // server send event, OR exception bits below
+	eventRestart = byte(0x00) // communication restart
+
+	eventSendReadException  = byte(0x01) // exception 1-3
+	eventSendAbortException = byte(0x02) // exception 4
+	eventSendBusyException  = byte(0x04) // exception 5-6
+	eventSendNAKException   = byte(0x08) // exception 7
+)
+
+type unitDiagnostics struct {
+	counters DiagnosticCounters
+
+	// Ring of the most recent events; head is the next slot.
+	log  [eventLogSize]byte
+	head int
+	n    int
+}
+
+// NewDiagnostics creates empty counters.
+func NewDiagnostics() *Diagnostics {
+	return &Diagnostics{units: make(map[memorycore.MemoryID]*unitDiagnostics)}
+}
+
+// Counters returns a snapshot of one memory's counters.
+func (d *Diagnostics) Counters(mid memorycore.MemoryID) DiagnosticCounters {
+	if d == nil {
+		return DiagnosticCounters{}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if u := d.units[mid]; u != nil {
+		return u.counters
+	}
+	return DiagnosticCounters{}
+}
+
+// unitLocked returns (creating) the counters of a memory. Caller holds d.mu.
+func (d *Diagnostics) unitLocked(mid memorycore.MemoryID) *unitDiagnostics {
+	u := d.units[mid]
+	if u == nil {
+		u = &unitDiagnostics{}
+		d.units[mid] = u
+	}
+	return u
+}
+
+func (u *unitDiagnostics) logEvent(ev byte) {
+	u.log[u.head] = ev
+	u.head = (u.head + 1) % eventLogSize
+	if u.n < eventLogSize {
+		u.n++
+	}
+}
+
+// received counts a request for a local unit.
+func (d *Diagnostics) received(mid memorycore.MemoryID) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	u := d.unitLocked(mid)
+	u.counters.BusMessages++
+	u.logEvent(eventReceive)
+	d.mu.Unlock()
+}
+
+// processed counts a request that passed authority.
+func (d *Diagnostics) processed(mid memorycore.MemoryID) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.unitLocked(mid).counters.ServerMessages++
+	d.mu.Unlock()
+}
+
+// responded counts the response sent for a request.
+func (d *Diagnostics) responded(mid memorycore.MemoryID, fc uint8, pdu []byte) {
+	if d == nil || len(pdu) == 0 {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	u := d.unitLocked(mid)
+
+	if pdu[0]&0x80 == 0 {
+		if fc != 11 {
+			u.counters.CommEvents++
+		}
+		u.logEvent(eventSend)
+		return
+	}
+
+	u.counters.BusExceptions++
+
+	var code byte
+	if len(pdu) > 1 {
+		code = pdu[1]
+	}
+	ev := eventSend
+	switch {
+	case code >= 1 && code <= 3:
+		ev |= eventSendReadException
+	case code == 4:
+		ev |= eventSendAbortException
+	case code == 5 || code == 6:
+		ev |= eventSendBusyException
+	case code == 7:
+		ev |= eventSendNAKException
+	}
+	if code == 0x06 {
+		u.counters.ServerBusy++
+	}
+	u.logEvent(ev)
+}
+
+// Dispatch serves FC8, FC11 and FC12 for a local unit.
+// It returns nil for other function codes (or when d is nil).
+func (d *Diagnostics) Dispatch(req *Request) []byte {
+	if d == nil {
+		return nil
+	}
+
+	mid := memorycore.MemoryID{Port: req.Port, UnitID: uint16(req.UnitID)}
+
+	switch req.FunctionCode {
+	case 8:
+		return d.handleDiagnostics(mid, req)
+	case 11:
+		return d.handleCommEventCounter(mid, req)
+	case 12:
+		return d.handleCommEventLog(mid, req)
+	default:
+		return nil
+	}
+}
+
+// FC8 sub-functions.
+const (
+	diagReturnQueryData       = uint16(0x00)
+	diagRestartComm           = uint16(0x01)
+	diagClearCounters         = uint16(0x0A)
+	diagBusMessageCount       = uint16(0x0B)
+	diagBusCommErrorCount     = uint16(0x0C)
+	diagBusExceptionCount     = uint16(0x0D)
+	diagServerMessageCount    = uint16(0x0E)
+	diagServerNoResponseCount = uint16(0x0F)
+	diagServerNAKCount        = uint16(0x10)
+	diagServerBusyCount       = uint16(0x11)
+	diagBusCharOverrunCount   = uint16(0x12)
+)
+
+func (d *Diagnostics) handleDiagnostics(mid memorycore.MemoryID, req *Request) []byte {
+	p := req.Payload
+	if len(p) < 2 {
+		// Illegal Data Value
+		return BuildExceptionPDU(req.FunctionCode, 0x03)
+	}
+	sub := binary.BigEndian.Uint16(p[0:2])
+	data := p[2:]
+
+	if sub == diagReturnQueryData {
+		return append([]byte{req.FunctionCode}, p...)
+	}
+
+	if sub < diagRestartComm || (sub > diagRestartComm && sub < diagClearCounters) || sub > diagBusCharOverrunCount {
+		// Illegal Function (sub-function not supported)
+		return BuildExceptionPDU(req.FunctionCode, 0x01)
+	}
+	if len(data) != 2 {
+		// Illegal Data Value
+		return BuildExceptionPDU(req.FunctionCode, 0x03)
+	}
+	value := binary.BigEndian.Uint16(data)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	u := d.unitLocked(mid)
+
+	switch sub {
+	case diagRestartComm:
+		if value != 0x0000 && value != 0xFF00 {
+			// Illegal Data Value
+			return BuildExceptionPDU(req.FunctionCode, 0x03)
+		}
+		u.counters = DiagnosticCounters{}
+		if value == 0xFF00 {
+			u.head, u.n = 0, 0
+		}
+		u.logEvent(eventRestart)
+		return append([]byte{req.FunctionCode}, p...)
+
+	case diagClearCounters:
+		if value != 0 {
+			// Illegal Data Value
+			return BuildExceptionPDU(req.FunctionCode, 0x03)
+		}
+		u.counters = DiagnosticCounters{}
+		return append([]byte{req.FunctionCode}, p...)
+	}
+
+	if value != 0 {
+		// Illegal Data Value
+		return BuildExceptionPDU(req.FunctionCode, 0x03)
+	}
+
+	var n uint16
+	c := u.counters
+	switch sub {
+	case diagBusMessageCount:
+		n = c.BusMessages
+	case diagBusCommErrorCount:
+		n = c.BusCommErrors
+	case diagBusExceptionCount:
+		n = c.BusExceptions
+	case diagServerMessageCount:
+		n = c.ServerMessages
+	case diagServerNoResponseCount:
+		n = c.ServerNoResponse
+	case diagServerNAKCount:
+		n = c.ServerNAK
+	case diagServerBusyCount:
+		n = c.ServerBusy
+	case diagBusCharOverrunCount:
+		n = c.CharOverruns
+	}
+
+	out := make([]byte, 5)
+	out[0] = req.FunctionCode
+	binary.BigEndian.PutUint16(out[1:3], sub)
+	binary.BigEndian.PutUint16(out[3:5], n)
+	return out
+}
+
+// handleCommEventCounter serves FC11: Status(2) EventCount(2).
+// Status is always 0x0000 (no program command in progress).
+func (d *Diagnostics) handleCommEventCounter(mid memorycore.MemoryID, req *Request) []byte {
+	if len(req.Payload) != 0 {
+		// Illegal Data Value
+		return BuildExceptionPDU(req.FunctionCode, 0x03)
+	}
+
+	d.mu.Lock()
+	events := d.unitLocked(mid).counters.CommEvents
+	d.mu.Unlock()
+
+	out := make([]byte, 5)
+	out[0] = req.FunctionCode
+	binary.BigEndian.PutUint16(out[3:5], events)
+	return out
+}
+
+// handleCommEventLog serves FC12:
+// ByteCount(1) Status(2) EventCount(2) MessageCount(2) Events(0..64),
+// most recent event first.
+func (d *Diagnostics) handleCommEventLog(mid memorycore.MemoryID, req *Request) []byte {
+	if len(req.Payload) != 0 {
+		// Illegal Data Value
+		return BuildExceptionPDU(req.FunctionCode, 0x03)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	u := d.unitLocked(mid)
+
+	out := make([]byte, 8, 8+u.n)
+	out[0] = req.FunctionCode
+	out[1] = byte(6 + u.n)
+	binary.BigEndian.PutUint16(out[4:6], u.counters.CommEvents)
+	binary.BigEndian.PutUint16(out[6:8], u.counters.BusMessages)
+	for i := 1; i <= u.n; i++ {
+		out = append(out, u.log[(u.head-i+eventLogSize)%eventLogSize])
+	}
+	return out
+}
@@ -17,6 +17,13 @@ import (
 //   FC6  - Write Single Register (Holding Registers only)
 //   FC15 - Write Multiple Coils
 //   FC16 - Write Multiple Registers (Holding Registers only)
+//   FC20 - Read File Record
+//   FC21 - Write File Record (all sub-requests atomic)
+//   FC22 - Mask Write Register (Holding Registers only, atomic)
+//   FC23 - Read/Write Multiple Registers (Holding Registers only, atomic)
+//   FC43 - MEI 0x0E Read Device Identification
+//
+// FC8, FC11 and FC12 are served by Diagnostics (see Server).
 func DispatchMemory(store *memorycore.Store, req *Request) []byte {
 	switch req.FunctionCode {
 	case 1:
@@ -35,12 +42,33 @@ func DispatchMemory(store *memorycore.Store, req *Request) []byte {
 		return handleWriteMultipleCoils(store, req)
 	case 16:
 		return handleWriteMultipleRegs(store, req)
+	case 20:
+		return handleReadFileRecord(store, req)
+	case 21:
+		return handleWriteFileRecord(store, req)
+	case 22:
+		pdu, _ := handleMaskWriteReg(store, req)
+		return pdu
+	case 23:
+		return handleReadWriteMultipleRegs(store, req)
+	case 43:
+		return handleReadDeviceID(store, req)
 	default:
 		// Illegal Function
 		return BuildExceptionPDU(req.FunctionCode, 0x01)
 	}
 }
 
+// dispatchLocal is DispatchMemory for the server. An FC22 write also
+// returns the register value it stored, computed under the memory lock
+// (the response PDU carries only the masks).
+func dispatchLocal(store *memorycore.Store, req *Request) (pdu []byte, masked uint16) {
+	if req.FunctionCode == 22 {
+		return handleMaskWriteReg(store, req)
+	}
+	return DispatchMemory(store, req), 0
+}
+
 func resolveMemory(store *memorycore.Store, req *Request) (*memorycore.Memory, bool) {
 	memID := memorycore.MemoryID{
 		Port:   req.Port,
@@ -206,3 +234,68 @@ func handleWriteMultipleRegs(store *memorycore.Store, req *Request) []byte {
 
 	return BuildWriteMultipleResponsePDU(req.FunctionCode, decoded.Address, decoded.Quantity)
 }
+
+// handleMaskWriteReg is a read-modify-write under one memory lock,
+// so it cannot race with other writers of the same register.
+// It also returns the value stored (0 on exception).
+func handleMaskWriteReg(store *memorycore.Store, req *Request) ([]byte, uint16) {
+	decoded, err := DecodeMaskWriteReg(req.Payload)
+	if err != nil {
+		// Illegal Data Value
+		return BuildExceptionPDU(req.FunctionCode, 0x03), 0
+	}
+
+	mem, ok := resolveMemory(store, req)
+	if !ok {
+		// Illegal Data Address
+		return BuildExceptionPDU(req.FunctionCode, 0x02), 0
+	}
+
+	value, err := mem.MaskWriteReg(decoded.Address, decoded.AndMask, decoded.OrMask)
+	if err != nil {
+		// Illegal Data Address
+		return BuildExceptionPDU(req.FunctionCode, 0x02), 0
+	}
+
+	return BuildMaskWriteRegResponsePDU(decoded.Address, decoded.AndMask, decoded.OrMask), value
+}
+
+// FC23 quantity limits (Modbus application protocol).
+const (
+	maxReadWriteReadQty  = 125
+	maxReadWriteWriteQty = 121
+)
+
+// handleReadWriteMultipleRegs performs the write, then the read, under one
+// memory lock: no other write can interleave between them.
+func handleReadWriteMultipleRegs(store *memorycore.Store, req *Request) []byte {
+	decoded, err := DecodeReadWriteMultiple(req.Payload)
+	if err != nil ||
+		decoded.ReadQuantity == 0 || decoded.ReadQuantity > maxReadWriteReadQty ||
+		decoded.WriteQuantity == 0 || decoded.WriteQuantity > maxReadWriteWriteQty {
+		// Illegal Data Value
+		return BuildExceptionPDU(req.FunctionCode, 0x03)
+	}
+
+	mem, ok := resolveMemory(store, req)
+	if !ok {
+		// Illegal Data Address
+		return BuildExceptionPDU(req.FunctionCode, 0x02)
+	}
+
+	src := make([]byte, len(decoded.Values)*2)
+	for i, v := range decoded.Values {
+		binary.BigEndian.PutUint16(src[i*2:i*2+2], v)
+	}
+
+	buf := make([]byte, int(decoded.ReadQuantity)*2)
+	if err := mem.WriteReadRegs(
+		decoded.WriteAddress, decoded.WriteQuantity, src,
+		decoded.ReadAddress, decoded.ReadQuantity, buf,
+	); err != nil {
+		// Illegal Data Address
+		return BuildExceptionPDU(req.FunctionCode, 0x02)
+	}
+
+	return BuildReadWriteMultipleResponsePDU(buf)
+}
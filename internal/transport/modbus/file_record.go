@@ -0,0 +1,93 @@
+// internal/transport/modbus/file_record.go
+package modbus
+
+import (
+	"errors"
+
+	"MMA2.0/internal/memorycore"
+)
+
+// maxFileRecordResp bounds the FC20 response data length.
+const maxFileRecordResp = 0xF5
+
+func handleReadFileRecord(store *memorycore.Store, req *Request) []byte {
+	refs, err := DecodeReadFileRecord(req.Payload)
+	if errors.Is(err, errFileRefType) {
+		// Illegal Data Address
+		return BuildExceptionPDU(req.FunctionCode, 0x02)
+	}
+	if err != nil {
+		// Illegal Data Value
+		return BuildExceptionPDU(req.FunctionCode, 0x03)
+	}
+
+	n := 0
+	for _, ref := range refs {
+		n += 2 + int(ref.Length)*2
+	}
+	if n > maxFileRecordResp {
+		// Illegal Data Value (response would not fit in one PDU)
+		return BuildExceptionPDU(req.FunctionCode, 0x03)
+	}
+
+	mem, ok := resolveMemory(store, req)
+	if !ok {
+		// Illegal Data Address
+		return BuildExceptionPDU(req.FunctionCode, 0x02)
+	}
+
+	records := make([][]byte, 0, len(refs))
+	for _, ref := range refs {
+		buf := make([]byte, int(ref.Length)*2)
+		if err := mem.ReadFileRecords(ref.File, ref.Record, ref.Length, buf); err != nil {
+			// Illegal Data Address (no file area, bad file or record range)
+			return BuildExceptionPDU(req.FunctionCode, 0x02)
+		}
+		records = append(records, buf)
+	}
+
+	return BuildReadFileRecordResponsePDU(records)
+}
+
+// handleWriteFileRecord applies every sub-request under one memory lock:
+// either all records are written or none.
+func handleWriteFileRecord(store *memorycore.Store, req *Request) []byte {
+	subs, err := DecodeWriteFileRecord(req.Payload)
+	if errors.Is(err, errFileRefType) {
+		// Illegal Data Address
+		return BuildExceptionPDU(req.FunctionCode, 0x02)
+	}
+	if err != nil {
+		// Illegal Data Value
+		return BuildExceptionPDU(req.FunctionCode, 0x03)
+	}
+
+	mem, ok := resolveMemory(store, req)
+	if !ok {
+		// Illegal Data Address
+		return BuildExceptionPDU(req.FunctionCode, 0x02)
+	}
+
+	writes := make([]memorycore.Write, 0, len(subs))
+	for _, sub := range subs {
+		addr, err := mem.FileRecordAddress(sub.File, sub.Record, sub.Length)
+		if err != nil {
+			// Illegal Data Address
+			return BuildExceptionPDU(req.FunctionCode, 0x02)
+		}
+		writes = append(writes, memorycore.Write{
+			Area:    memorycore.AreaFileRecords,
+			Address: addr,
+			Count:   sub.Length,
+			Src:     sub.Data,
+		})
+	}
+
+	if _, err := mem.ApplyWrites(writes); err != nil {
+		// Illegal Data Address
+		return BuildExceptionPDU(req.FunctionCode, 0x02)
+	}
+
+	// Normal response is an echo of the request.
+	return append([]byte{req.FunctionCode}, req.Payload...)
+}
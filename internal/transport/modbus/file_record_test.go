@@ -0,0 +1,163 @@
+// internal/transport/modbus/file_record_test.go
+package modbus
+
+import (
+	"testing"
+
+	"MMA2.0/internal/memorycore"
+)
+
+// newFileStore serves unit 1 (3 files x 10 records) and unit 2 (no file area).
+func newFileStore(t *testing.T) (*memorycore.Store, *memorycore.Memory) {
+	t.Helper()
+
+	mem, err := memorycore.NewMemory(memorycore.MemoryLayouts{
+		FileRecords: &memorycore.FileLayout{Files: 3, Records: 10},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := memorycore.NewMemory(memorycore.MemoryLayouts{
+		HoldingRegs: &memorycore.AreaLayout{Start: 0, Size: 4},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := memorycore.NewStore()
+	if err := store.Add(memorycore.MemoryID{Port: 502, UnitID: 1}, mem); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Add(memorycore.MemoryID{Port: 502, UnitID: 2}, plain); err != nil {
+		t.Fatal(err)
+	}
+	return store, mem
+}
+
+// fileReq builds an FC20/21 request: byte count, then the sub-requests.
+func fileReq(unit, fc uint8, subs ...[]byte) *Request {
+	var body []byte
+	for _, s := range subs {
+		body = append(body, s...)
+	}
+	return &Request{Port: 502, UnitID: unit, FunctionCode: fc, Payload: append([]byte{byte(len(body))}, body...)}
+}
+
+// fileRef is one sub-request header: RefType File Record Length.
+func fileRef(refType byte, file, record, length uint16) []byte {
+	return []byte{refType, byte(file >> 8), byte(file), byte(record >> 8), byte(record), byte(length >> 8), byte(length)}
+}
+
+func TestReadFileRecord(t *testing.T) {
+	store, mem := newFileStore(t)
+	if err := mem.WriteFileRecords(1, 0, 2, []byte{0x00, 0x01, 0x00, 0x02}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mem.WriteFileRecords(3, 9, 1, []byte{0xAB, 0xCD}); err != nil {
+		t.Fatal(err)
+	}
+
+	pdu := DispatchMemory(store, fileReq(1, 20, fileRef(6, 1, 0, 2), fileRef(6, 3, 9, 1)))
+	want := []byte{
+		20, 10,
+		5, 6, 0x00, 0x01, 0x00, 0x02,
+		3, 6, 0xAB, 0xCD,
+	}
+	if string(pdu) != string(want) {
+		t.Fatalf("response % x, want % x", pdu, want)
+	}
+}
+
+func TestReadFileRecordExceptions(t *testing.T) {
+	store, _ := newFileStore(t)
+
+	// 35 sub-requests fill the request (35 * 7 = 245 bytes), but their
+	// response (35 * (2 + 20) bytes) exceeds one PDU.
+	var large [][]byte
+	for i := 0; i < 35; i++ {
+		large = append(large, fileRef(6, 1, 0, 10))
+	}
+
+	for _, tc := range []struct {
+		name string
+		req  *Request
+		code byte
+	}{
+		{"length zero", fileReq(1, 20, fileRef(6, 1, 0, 0)), 0x03},
+		{"length zero after valid", fileReq(1, 20, fileRef(6, 1, 0, 1), fileRef(6, 1, 0, 0)), 0x03},
+		{"byte count mismatch", &Request{Port: 502, UnitID: 1, FunctionCode: 20, Payload: append([]byte{14}, fileRef(6, 1, 0, 1)...)}, 0x03},
+		{"partial sub-request", &Request{Port: 502, UnitID: 1, FunctionCode: 20, Payload: append([]byte{6}, fileRef(6, 1, 0, 1)[:6]...)}, 0x03},
+		{"response too large", fileReq(1, 20, large...), 0x03},
+		{"reference type", fileReq(1, 20, fileRef(5, 1, 0, 1)), 0x02},
+		{"file zero", fileReq(1, 20, fileRef(6, 0, 0, 1)), 0x02},
+		{"no such file", fileReq(1, 20, fileRef(6, 4, 0, 1)), 0x02},
+		{"crosses file end", fileReq(1, 20, fileRef(6, 1, 9, 2)), 0x02},
+		{"no file area", fileReq(2, 20, fileRef(6, 1, 0, 1)), 0x02},
+		{"unknown unit", fileReq(9, 20, fileRef(6, 1, 0, 1)), 0x02},
+	} {
+		pdu := DispatchMemory(store, tc.req)
+		if want := []byte{0x80 | 20, tc.code}; string(pdu) != string(want) {
+			t.Errorf("%s: response % x, want % x", tc.name, pdu, want)
+		}
+	}
+}
+
+func TestWriteFileRecord(t *testing.T) {
+	store, mem := newFileStore(t)
+
+	req := fileReq(1, 21,
+		append(fileRef(6, 2, 4, 2), 0x11, 0x11, 0x22, 0x22),
+		append(fileRef(6, 3, 0, 1), 0x33, 0x33),
+	)
+	pdu := DispatchMemory(store, req)
+
+	// Normal response echoes the request.
+	if want := append([]byte{21}, req.Payload...); string(pdu) != string(want) {
+		t.Fatalf("response % x, want % x", pdu, want)
+	}
+
+	flat := make([]byte, 2*30)
+	if err := mem.ReadRegs(memorycore.AreaFileRecords, 0, 30, flat); err != nil {
+		t.Fatal(err)
+	}
+	want := make([]byte, 2*30)
+	copy(want[2*14:], []byte{0x11, 0x11, 0x22, 0x22}) // file 2, record 4
+	copy(want[2*20:], []byte{0x33, 0x33})             // file 3, record 0
+	if string(flat) != string(want) {
+		t.Fatalf("file area % x, want % x", flat, want)
+	}
+}
+
+func TestWriteFileRecordAtomic(t *testing.T) {
+	store, mem := newFileStore(t)
+
+	for _, tc := range []struct {
+		name string
+		req  *Request
+		code byte
+	}{
+		// The first sub-request is valid; the second fails: nothing is written.
+		{"second out of range", fileReq(1, 21,
+			append(fileRef(6, 1, 0, 1), 0xFF, 0xFF),
+			append(fileRef(6, 4, 0, 1), 0xFF, 0xFF)), 0x02},
+		{"second reference type", fileReq(1, 21,
+			append(fileRef(6, 1, 0, 1), 0xFF, 0xFF),
+			append(fileRef(7, 1, 1, 1), 0xFF, 0xFF)), 0x02},
+		{"length zero", fileReq(1, 21, fileRef(6, 1, 0, 0), []byte{0, 0}), 0x03},
+		{"short data", fileReq(1, 21, append(fileRef(6, 1, 0, 2), 0xFF, 0xFF)), 0x03},
+		{"no file area", fileReq(2, 21, append(fileRef(6, 1, 0, 1), 0xFF, 0xFF)), 0x02},
+	} {
+		pdu := DispatchMemory(store, tc.req)
+		if want := []byte{0x80 | 21, tc.code}; string(pdu) != string(want) {
+			t.Errorf("%s: response % x, want % x", tc.name, pdu, want)
+		}
+	}
+
+	flat := make([]byte, 2*30)
+	if err := mem.ReadRegs(memorycore.AreaFileRecords, 0, 30, flat); err != nil {
+		t.Fatal(err)
+	}
+	if string(flat) != string(make([]byte, 2*30)) {
+		t.Fatalf("file area written by a rejected request: % x", flat)
+	}
+}
@@ -0,0 +1,124 @@
+// internal/transport/modbus/gateway.go
+package modbus
+
+import (
+	"errors"
+	"log"
+	"net"
+	"sync"
+	"time"
+)
+
+// Gateway exception codes.
+const (
+	ExceptionGatewayPathUnavailable = uint8(0x0A)
+	ExceptionGatewayTargetFailed    = uint8(0x0B)
+)
+
+// DefaultGatewayTimeout bounds one backend exchange.
+const DefaultGatewayTimeout = time.Second
+
+// Route forwards one local unit ID to a backend Modbus TCP device.
+type Route struct {
+	UnitID       uint8  // unit ID on this listener
+	Address      string // backend host:port
+	RemoteUnitID uint8  // unit ID on the backend
+	Timeout      time.Duration
+}
+
+// Gateway is a per-listener routing table.
+//
+// Requests are forwarded as opaque PDUs: any function code the authority
+// allows is passed through, and the backend's answer (data or exception)
+// is relayed unchanged. The client's transaction ID is preserved.
+//
+// One backend connection is kept per route; requests to the same route
+// are serialized on it. A kept connection the device closed while idle
+// is redialed once before the request fails.
+type Gateway struct {
+	routes map[uint8]*backend
+}
+
+type backend struct {
+	route Route
+
+	mu     sync.Mutex
+	client *Client
+}
+
+// NewGateway builds a gateway from routes (unit IDs must be unique).
+func NewGateway(routes []Route) *Gateway {
+	g := &Gateway{routes: make(map[uint8]*backend, len(routes))}
+	for _, r := range routes {
+		if r.Timeout <= 0 {
+			r.Timeout = DefaultGatewayTimeout
+		}
+		g.routes[r.UnitID] = &backend{route: r}
+	}
+	return g
+}
+
+// Routes reports whether unitID is forwarded.
+func (g *Gateway) Routes(unitID uint8) bool {
+	if g == nil {
+		return false
+	}
+	_, ok := g.routes[unitID]
+	return ok
+}
+
+// Forward sends req to its backend and returns the response PDU.
+//
+//	backend unreachable          → 0x0A Gateway Path Unavailable
+//	no / invalid backend answer  → 0x0B Gateway Target Device Failed to Respond
+//
+// An exchange that fails on a reused connection (other than by timeout)
+// is retried once on a fresh connection; 0x0B is returned only when the
+// fresh connection fails too. Timeouts are not retried: the device may
+// have applied the request.
+func (g *Gateway) Forward(req *Request) []byte {
+	b := g.routes[req.UnitID]
+	if b == nil {
+		return BuildExceptionPDU(req.FunctionCode, ExceptionGatewayPathUnavailable)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	pdu := append([]byte{req.FunctionCode}, req.Payload...)
+
+	reused := b.client != nil
+	for {
+		if b.client == nil {
+			c, err := Dial(b.route.Address, b.route.Timeout)
+			if err != nil {
+				log.Printf("modbus gateway: unit %d -> %s: %v", req.UnitID, b.route.Address, err)
+				return BuildExceptionPDU(req.FunctionCode, ExceptionGatewayPathUnavailable)
+			}
+			b.client = c
+		}
+
+		resp, err := b.client.Do(req.TransactionID, b.route.RemoteUnitID, pdu)
+		if err == nil {
+			return resp
+		}
+
+		// The connection state is unknown after a failed exchange.
+		_ = b.client.Close()
+		b.client = nil
+
+		if reused && !isTimeout(err) {
+			// Likely closed by the device while idle: redial once.
+			reused = false
+			continue
+		}
+
+		log.Printf("modbus gateway: unit %d -> %s: %v", req.UnitID, b.route.Address, err)
+		return BuildExceptionPDU(req.FunctionCode, ExceptionGatewayTargetFailed)
+	}
+}
+
+func isTimeout(err error) bool {
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
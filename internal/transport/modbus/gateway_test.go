@@ -0,0 +1,145 @@
+// internal/transport/modbus/gateway_test.go
+package modbus
+
+import (
+	"bufio"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// backendResp is the FC3 answer of the fake backend (one register 0x1234).
+var backendResp = []byte{3, 2, 0x12, 0x34}
+
+// fakeBackend accepts connections and runs serve on each.
+type fakeBackend struct {
+	ln      net.Listener
+	accepts atomic.Int32
+}
+
+func newFakeBackend(t *testing.T, serve func(c net.Conn, r *bufio.Reader)) *fakeBackend {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	b := &fakeBackend{ln: ln}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			b.accepts.Add(1)
+			go func() {
+				defer c.Close()
+				serve(c, bufio.NewReader(c))
+			}()
+		}
+	}()
+	return b
+}
+
+// answer reads one request and writes backendResp.
+func answer(c net.Conn, r *bufio.Reader) bool {
+	req, err := ReadRequest(r, 0)
+	if err != nil {
+		return false
+	}
+	_, err = c.Write(BuildResponse(req, backendResp))
+	return err == nil
+}
+
+func gatewayTo(addr string) *Gateway {
+	return NewGateway([]Route{{UnitID: 1, Address: addr, RemoteUnitID: 7, Timeout: 200 * time.Millisecond}})
+}
+
+func fc3(tid uint16) *Request {
+	return &Request{TransactionID: tid, UnitID: 1, FunctionCode: 3, Payload: []byte{0, 0, 0, 1}}
+}
+
+func TestGatewayRedialsIdleClosedConnection(t *testing.T) {
+	// The device answers one request per connection, then closes it
+	// (as a device dropping an idle client would).
+	b := newFakeBackend(t, func(c net.Conn, r *bufio.Reader) { answer(c, r) })
+	g := gatewayTo(b.ln.Addr().String())
+
+	for tid := uint16(1); tid <= 3; tid++ {
+		if got := g.Forward(fc3(tid)); string(got) != string(backendResp) {
+			t.Fatalf("request %d: response % x, want % x", tid, got, backendResp)
+		}
+	}
+	if n := b.accepts.Load(); n != 3 {
+		t.Fatalf("accepts = %d, want 3", n)
+	}
+}
+
+func TestGatewayKeepsConnection(t *testing.T) {
+	b := newFakeBackend(t, func(c net.Conn, r *bufio.Reader) {
+		for answer(c, r) {
+		}
+	})
+	g := gatewayTo(b.ln.Addr().String())
+
+	for tid := uint16(1); tid <= 3; tid++ {
+		if got := g.Forward(fc3(tid)); string(got) != string(backendResp) {
+			t.Fatalf("request %d: response % x, want % x", tid, got, backendResp)
+		}
+	}
+	if n := b.accepts.Load(); n != 1 {
+		t.Fatalf("accepts = %d, want 1", n)
+	}
+}
+
+func TestGatewayFreshConnectionFails(t *testing.T) {
+	// Accepts and closes without answering: a fresh connection is not retried.
+	b := newFakeBackend(t, func(c net.Conn, r *bufio.Reader) {})
+	g := gatewayTo(b.ln.Addr().String())
+
+	want := []byte{3 | 0x80, ExceptionGatewayTargetFailed}
+	if got := g.Forward(fc3(1)); string(got) != string(want) {
+		t.Fatalf("response % x, want % x", got, want)
+	}
+	if n := b.accepts.Load(); n != 1 {
+		t.Fatalf("accepts = %d, want 1", n)
+	}
+}
+
+func TestGatewayTimeoutNotRetried(t *testing.T) {
+	// Answers the first request, then goes silent on the same connection.
+	b := newFakeBackend(t, func(c net.Conn, r *bufio.Reader) {
+		if answer(c, r) {
+			_, _ = ReadRequest(r, 0)
+			time.Sleep(time.Second)
+		}
+	})
+	g := gatewayTo(b.ln.Addr().String())
+
+	if got := g.Forward(fc3(1)); string(got) != string(backendResp) {
+		t.Fatalf("response % x, want % x", got, backendResp)
+	}
+	want := []byte{3 | 0x80, ExceptionGatewayTargetFailed}
+	if got := g.Forward(fc3(2)); string(got) != string(want) {
+		t.Fatalf("response % x, want % x", got, want)
+	}
+	if n := b.accepts.Load(); n != 1 {
+		t.Fatalf("accepts = %d, want 1", n)
+	}
+}
+
+func TestGatewayUnreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	want := []byte{3 | 0x80, ExceptionGatewayPathUnavailable}
+	if got := gatewayTo(addr).Forward(fc3(1)); string(got) != string(want) {
+		t.Fatalf("response % x, want % x", got, want)
+	}
+}
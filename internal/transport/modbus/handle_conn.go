@@ -11,12 +11,18 @@ import (
 	"MMA2.0/internal/memorycore"
 )
 
-// HandleConn handles a single Modbus TCP connection.
+// HandleConn handles a single Modbus TCP connection (local memories only).
 func HandleConn(
 	conn net.Conn,
 	store *memorycore.Store,
 	auth *authority.Authority,
 ) {
+	(&Server{Store: store, Auth: auth}).HandleConn(conn)
+}
+
+// HandleConn handles a single Modbus TCP connection.
+// Requests for gateway-routed unit IDs are forwarded after authorization.
+func (s *Server) HandleConn(conn net.Conn) {
 	defer conn.Close()
 
 	// Extract local listening port (authoritative)
@@ -55,33 +61,16 @@ func HandleConn(
 		}
 
 		// --------------------
-		// STATE SEALING
-		// Presence-based: if state_sealing is configured and flag == 0 → Device Busy
+		// AUTHORITY
+		// State sealing (Device Busy) + access rules.
+		// Evaluate is the ONLY place that decides Device Busy.
 		// --------------------
-		if mem, ok := store.Get(mid); ok {
-			if seal := mem.StateSealing(); seal != nil {
-				buf := []byte{0}
-				if err := mem.ReadBits(seal.Area, seal.Address, 1, buf); err != nil {
-					pdu := BuildExceptionPDU(req.FunctionCode, 0x06) // Device Busy
-					frame := BuildResponse(req, pdu)
-					_, _ = conn.Write(frame)
-					continue
-				}
-
-				// 0 = sealed, 1 = unsealed
-				if (buf[0] & 0x01) == 0 {
-					pdu := BuildExceptionPDU(req.FunctionCode, 0x06) // Device Busy
-					frame := BuildResponse(req, pdu)
-					_, _ = conn.Write(frame)
-					continue
-				}
-			}
+		local := !s.Gateway.Routes(req.UnitID)
+		if local {
+			s.Diagnostics.received(mid)
 		}
 
-		// --------------------
-		// ACCESS CONTROL (unchanged)
-		// --------------------
-		decision := auth.Evaluate(authority.Request{
+		decision := s.Auth.Evaluate(authority.Request{
 			MemoryID:     mid,
 			SourceIP:     srcIP,
 			FunctionCode: req.FunctionCode,
@@ -89,15 +78,38 @@ func HandleConn(
 
 		if !decision.Allowed {
 			pdu := BuildExceptionPDU(req.FunctionCode, decision.ExceptionCode)
+			if local {
+				s.Diagnostics.responded(mid, req.FunctionCode, pdu)
+			}
 			frame := BuildResponse(req, pdu)
 			_, _ = conn.Write(frame)
 			continue
 		}
 
 		// --------------------
-		// DISPATCH
+		// DISPATCH (gateway route, else local memory)
 		// --------------------
-		pdu := DispatchMemory(store, req)
+		var pdu []byte
+		if !local {
+			pdu = s.Gateway.Forward(req)
+		} else {
+			s.Diagnostics.processed(mid)
+
+			var masked uint16
+			pdu = s.Diagnostics.Dispatch(req)
+			if pdu == nil {
+				pdu, masked = dispatchLocal(s.Store, req)
+			}
+
+			if s.Observer != nil {
+				if w, ok := appliedWrite(req, pdu, masked); ok {
+					w.SourceIP = srcIP
+					s.Observer.ObserveWrite(w)
+				}
+			}
+
+			s.Diagnostics.responded(mid, req.FunctionCode, pdu)
+		}
 		if pdu == nil {
 			return
 		}
@@ -3,6 +3,7 @@ package modbus
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 )
 
@@ -93,3 +94,150 @@ func DecodeWriteMultipleBits(pdu []byte) (*WriteMultipleBitsPDU, error) {
 		Data:     data,
 	}, nil
 }
+
+// DecodeReadWriteMultiple decodes FC 23 (read/write multiple registers)
+// Payload: ReadAddress(2) ReadQuantity(2) WriteAddress(2) WriteQuantity(2)
+// ByteCount(1) Values(ByteCount)
+func DecodeReadWriteMultiple(pdu []byte) (*ReadWriteMultiplePDU, error) {
+	if len(pdu) < 9 {
+		return nil, fmt.Errorf("invalid read/write multiple length")
+	}
+
+	writeQty := binary.BigEndian.Uint16(pdu[6:8])
+	byteCount := int(pdu[8])
+
+	if len(pdu[9:]) != byteCount {
+		return nil, fmt.Errorf("byte count mismatch")
+	}
+
+	if byteCount != int(writeQty)*2 {
+		return nil, fmt.Errorf("invalid register byte count")
+	}
+
+	values := make([]uint16, 0, writeQty)
+	for i := 0; i < byteCount; i += 2 {
+		values = append(values, binary.BigEndian.Uint16(pdu[9+i:9+i+2]))
+	}
+
+	return &ReadWriteMultiplePDU{
+		ReadAddress:   binary.BigEndian.Uint16(pdu[0:2]),
+		ReadQuantity:  binary.BigEndian.Uint16(pdu[2:4]),
+		WriteAddress:  binary.BigEndian.Uint16(pdu[4:6]),
+		WriteQuantity: writeQty,
+		Values:        values,
+	}, nil
+}
+
+// DecodeMaskWriteReg decodes FC 22 (mask write register)
+// Payload: Address(2) AndMask(2) OrMask(2)
+func DecodeMaskWriteReg(pdu []byte) (*MaskWriteRegPDU, error) {
+	if len(pdu) != 6 {
+		return nil, fmt.Errorf("invalid mask write length")
+	}
+
+	return &MaskWriteRegPDU{
+		Address: binary.BigEndian.Uint16(pdu[0:2]),
+		AndMask: binary.BigEndian.Uint16(pdu[2:4]),
+		OrMask:  binary.BigEndian.Uint16(pdu[4:6]),
+	}, nil
+}
+
+// DecodeReadDeviceID decodes FC 43 / MEI 0x0E (read device identification)
+// Payload: MEIType(1) ReadDevIdCode(1) ObjectID(1)
+func DecodeReadDeviceID(pdu []byte) (*ReadDeviceIDPDU, error) {
+	if len(pdu) != 3 {
+		return nil, fmt.Errorf("invalid read device id length")
+	}
+	if pdu[0] != MEIReadDeviceID {
+		return nil, fmt.Errorf("unsupported MEI type 0x%02X", pdu[0])
+	}
+
+	return &ReadDeviceIDPDU{
+		ReadCode: pdu[1],
+		ObjectID: pdu[2],
+	}, nil
+}
+
+// fileRefType is the only reference type defined for FC 20/21.
+const fileRefType = 6
+
+// errFileRefType reports a sub-request with a reference type other than 6.
+var errFileRefType = errors.New("invalid file reference type")
+
+// DecodeReadFileRecord decodes FC 20 (read file record)
+// Payload: ByteCount(1) then N x [RefType(1) File(2) Record(2) Length(2)]
+func DecodeReadFileRecord(pdu []byte) ([]FileRecordRef, error) {
+	if len(pdu) < 1 {
+		return nil, fmt.Errorf("invalid read file record length")
+	}
+
+	byteCount := int(pdu[0])
+	if len(pdu[1:]) != byteCount {
+		return nil, fmt.Errorf("byte count mismatch")
+	}
+	if byteCount < 7 || byteCount > 0xF5 || byteCount%7 != 0 {
+		return nil, fmt.Errorf("invalid file record byte count")
+	}
+
+	refs := make([]FileRecordRef, 0, byteCount/7)
+	for i := 1; i < len(pdu); i += 7 {
+		if pdu[i] != fileRefType {
+			return nil, errFileRefType
+		}
+		ref := FileRecordRef{
+			File:   binary.BigEndian.Uint16(pdu[i+1 : i+3]),
+			Record: binary.BigEndian.Uint16(pdu[i+3 : i+5]),
+			Length: binary.BigEndian.Uint16(pdu[i+5 : i+7]),
+		}
+		if ref.Length == 0 {
+			return nil, fmt.Errorf("invalid file record length")
+		}
+		refs = append(refs, ref)
+	}
+
+	return refs, nil
+}
+
+// DecodeWriteFileRecord decodes FC 21 (write file record)
+// Payload: ByteCount(1) then N x [RefType(1) File(2) Record(2) Length(2) Data(Length*2)]
+func DecodeWriteFileRecord(pdu []byte) ([]FileRecordWrite, error) {
+	if len(pdu) < 1 {
+		return nil, fmt.Errorf("invalid write file record length")
+	}
+
+	byteCount := int(pdu[0])
+	if len(pdu[1:]) != byteCount {
+		return nil, fmt.Errorf("byte count mismatch")
+	}
+	if byteCount < 9 || byteCount > 0xFB {
+		return nil, fmt.Errorf("invalid file record byte count")
+	}
+
+	var writes []FileRecordWrite
+	for i := 1; i < len(pdu); {
+		if len(pdu[i:]) < 7 {
+			return nil, fmt.Errorf("truncated file record sub-request")
+		}
+		if pdu[i] != fileRefType {
+			return nil, errFileRefType
+		}
+
+		ref := FileRecordRef{
+			File:   binary.BigEndian.Uint16(pdu[i+1 : i+3]),
+			Record: binary.BigEndian.Uint16(pdu[i+3 : i+5]),
+			Length: binary.BigEndian.Uint16(pdu[i+5 : i+7]),
+		}
+		n := int(ref.Length) * 2
+		if ref.Length == 0 || len(pdu[i+7:]) < n {
+			return nil, fmt.Errorf("invalid file record data length")
+		}
+
+		data := make([]byte, n)
+		copy(data, pdu[i+7:i+7+n])
+		writes = append(writes, FileRecordWrite{FileRecordRef: ref, Data: data})
+
+		i += 7 + n
+	}
+
+	return writes, nil
+}
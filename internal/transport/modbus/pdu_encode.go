@@ -30,6 +30,40 @@ func BuildWriteMultipleResponsePDU(fc uint8, addr uint16, qty uint16) []byte {
 	return out
 }
 
+// BuildMaskWriteRegResponsePDU builds FC 22 response (echo of the request)
+func BuildMaskWriteRegResponsePDU(addr, andMask, orMask uint16) []byte {
+	out := make([]byte, 7)
+	out[0] = 22
+	binary.BigEndian.PutUint16(out[1:3], addr)
+	binary.BigEndian.PutUint16(out[3:5], andMask)
+	binary.BigEndian.PutUint16(out[5:7], orMask)
+	return out
+}
+
+// BuildReadWriteMultipleResponsePDU builds FC 23 response
+// (same layout as a read response: ByteCount(1) + read registers)
+func BuildReadWriteMultipleResponsePDU(data []byte) []byte {
+	return BuildReadResponsePDU(23, data)
+}
+
+// BuildReadFileRecordResponsePDU builds FC 20 response:
+// RespDataLength(1) then per sub-request FileRespLength(1) RefType(1) Data
+func BuildReadFileRecordResponsePDU(records [][]byte) []byte {
+	n := 0
+	for _, r := range records {
+		n += 2 + len(r)
+	}
+
+	out := make([]byte, 2, 2+n)
+	out[0] = 20
+	out[1] = uint8(n)
+	for _, r := range records {
+		out = append(out, uint8(1+len(r)), fileRefType)
+		out = append(out, r...)
+	}
+	return out
+}
+
 // BuildExceptionPDU builds Modbus exception response
 func BuildExceptionPDU(fc uint8, code uint8) []byte {
 	return []byte{fc | 0x80, code}
@@ -26,3 +26,38 @@ type WriteMultipleBitsPDU struct {
 	Quantity uint16
 	Data     []byte
 }
+
+// ReadWriteMultiplePDU represents FC 23 (read/write multiple registers)
+type ReadWriteMultiplePDU struct {
+	ReadAddress   uint16
+	ReadQuantity  uint16
+	WriteAddress  uint16
+	WriteQuantity uint16
+	Values        []uint16
+}
+
+// MaskWriteRegPDU represents FC 22 (mask write register)
+type MaskWriteRegPDU struct {
+	Address uint16
+	AndMask uint16
+	OrMask  uint16
+}
+
+// ReadDeviceIDPDU represents FC 43 / MEI 0x0E (read device identification)
+type ReadDeviceIDPDU struct {
+	ReadCode uint8 // 1 basic, 2 regular, 3 extended (stream), 4 individual
+	ObjectID uint8
+}
+
+// FileRecordRef is one FC 20/21 sub-request (reference type 6).
+type FileRecordRef struct {
+	File   uint16
+	Record uint16
+	Length uint16 // in 16-bit records
+}
+
+// FileRecordWrite is one FC 21 sub-request with its data.
+type FileRecordWrite struct {
+	FileRecordRef
+	Data []byte // big-endian words, Length*2 bytes
+}
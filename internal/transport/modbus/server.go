@@ -0,0 +1,24 @@
+// internal/transport/modbus/server.go
+package modbus
+
+import (
+	"MMA2.0/internal/authority"
+	"MMA2.0/internal/memorycore"
+)
+
+// Server holds what a Modbus TCP listener serves:
+// local memories and, optionally, gateway routes.
+type Server struct {
+	Store *memorycore.Store
+	Auth  *authority.Authority
+
+	// Optional routing table for unit IDs without local memory.
+	Gateway *Gateway
+
+	// Optional observer of writes applied to local memory (egress).
+	Observer WriteObserver
+
+	// Optional diagnostic counters (FC8, FC11, FC12). May be shared
+	// between listeners: counters are keyed by MemoryID.
+	Diagnostics *Diagnostics
+}
@@ -0,0 +1,148 @@
+// internal/transport/modbus/state_sealing_test.go
+package modbus
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"MMA2.0/internal/authority"
+	"MMA2.0/internal/memorycore"
+)
+
+// The sealing flag is a coil: Modbus may write it, but only ingest
+// transports may drive PRE-RUN -> RUN (docs/01_STATE_SEALING.md).
+
+const sealFlag = uint16(5)
+
+func newSealedStore(t *testing.T, port uint16) (*memorycore.Store, *memorycore.Memory, memorycore.MemoryID) {
+	t.Helper()
+	mem, err := memorycore.NewMemory(memorycore.MemoryLayouts{
+		Coils: &memorycore.AreaLayout{Start: 0, Size: 16},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mem.SetStateSealing(memorycore.StateSealingDef{Area: memorycore.AreaCoils, Address: sealFlag}); err != nil {
+		t.Fatal(err)
+	}
+
+	mid := memorycore.MemoryID{Port: port, UnitID: 1}
+	store := memorycore.NewStore()
+	if err := store.Add(mid, mem); err != nil {
+		t.Fatal(err)
+	}
+	return store, mem, mid
+}
+
+func coil(t *testing.T, mem *memorycore.Memory, address uint16) byte {
+	t.Helper()
+	dst := make([]byte, 1)
+	if err := mem.ReadBits(memorycore.AreaCoils, address, 1, dst); err != nil {
+		t.Fatal(err)
+	}
+	return dst[0]
+}
+
+func TestDispatchFlagWriteKeepsPreRun(t *testing.T) {
+	store, mem, mid := newSealedStore(t, 502)
+
+	for _, req := range []*Request{
+		// FC5 write single coil ON.
+		{Port: mid.Port, UnitID: 1, FunctionCode: 5, Payload: []byte{0, byte(sealFlag), 0xFF, 0x00}},
+		// FC15 write multiple coils 0..7 = 0xFF.
+		{Port: mid.Port, UnitID: 1, FunctionCode: 15, Payload: []byte{0, 0, 0, 8, 1, 0xFF}},
+	} {
+		pdu := DispatchMemory(store, req)
+		if len(pdu) == 0 || pdu[0] != req.FunctionCode {
+			t.Fatalf("fc%d: response % x, want normal response", req.FunctionCode, pdu)
+		}
+		if coil(t, mem, sealFlag) != 1 {
+			t.Fatalf("fc%d: flag coil not written", req.FunctionCode)
+		}
+		if got := mem.Lifecycle(); got != memorycore.LifecyclePreRun {
+			t.Fatalf("fc%d: lifecycle %s, want PRE-RUN", req.FunctionCode, got)
+		}
+	}
+}
+
+func TestHandleConnSealing(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	store, mem, mid := newSealedStore(t, uint16(ln.Addr().(*net.TCPAddr).Port))
+
+	rule, err := authority.NewRule("test", []string{"127.0.0.1"}, []uint8{1, 5, 15})
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := authority.New()
+	auth.SetMemoryPolicy(mid, &authority.MemoryPolicy{Rules: []*authority.Rule{rule}})
+	auth.Sealing().Register(mid, mem)
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go HandleConn(conn, store, auth)
+		}
+	}()
+
+	c, err := Dial(ln.Addr().String(), 2*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	fc5 := func(value uint16) []byte {
+		pdu := []byte{5, 0, byte(sealFlag), 0, 0}
+		binary.BigEndian.PutUint16(pdu[3:5], value)
+		return pdu
+	}
+	fc15 := []byte{15, 0, 0, 0, 8, 1, 0xFF}
+
+	// PRE-RUN: every request is Device Busy, flag writes included.
+	for i, pdu := range [][]byte{fc5(0xFF00), fc15, {1, 0, 0, 0, 8}} {
+		resp, err := c.Do(uint16(i), 1, pdu)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []byte{pdu[0] | 0x80, authority.ExceptionDeviceBusy}; string(resp) != string(want) {
+			t.Fatalf("fc%d: response % x, want % x", pdu[0], resp, want)
+		}
+	}
+	if got := mem.Lifecycle(); got != memorycore.LifecyclePreRun {
+		t.Fatalf("lifecycle %s, want PRE-RUN", got)
+	}
+	if coil(t, mem, sealFlag) != 0 {
+		t.Fatal("flag coil written while sealed")
+	}
+
+	// Ingest unlocks.
+	if !auth.Sealing().ObserveIngestWrite(mid, memorycore.AreaCoils, sealFlag, 1, []byte{1}) {
+		t.Fatal("ingest flag write did not unlock")
+	}
+
+	// RUN: Modbus flag writes are applied and never re-seal.
+	for i, pdu := range [][]byte{fc5(0x0000), fc5(0xFF00), fc15} {
+		resp, err := c.Do(uint16(10+i), 1, pdu)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp) == 0 || resp[0] != pdu[0] {
+			t.Fatalf("fc%d: response % x, want normal response", pdu[0], resp)
+		}
+		if got := mem.Lifecycle(); got != memorycore.LifecycleRun {
+			t.Fatalf("fc%d: lifecycle %s, want RUN", pdu[0], got)
+		}
+	}
+	if coil(t, mem, sealFlag) != 1 {
+		t.Fatal("flag coil not written in RUN")
+	}
+}
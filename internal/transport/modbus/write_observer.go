@@ -0,0 +1,107 @@
+// internal/transport/modbus/write_observer.go
+package modbus
+
+import (
+	"encoding/binary"
+	"net/netip"
+
+	"MMA2.0/internal/memorycore"
+)
+
+// AppliedWrite describes a Modbus write that reached local memory.
+type AppliedWrite struct {
+	MemoryID     memorycore.MemoryID
+	SourceIP     netip.Addr
+	FunctionCode uint8
+
+	Area    memorycore.Area
+	Address uint16
+	Count   uint16
+
+	// Src holds the written values in memorycore encoding:
+	// packed bits (LSB-first) or big-endian registers.
+	Src []byte
+}
+
+// WriteObserver is notified after a Modbus write succeeded.
+// It is called on the connection goroutine and must not block.
+type WriteObserver interface {
+	ObserveWrite(w AppliedWrite)
+}
+
+// appliedWrite derives the written range of a successful write request.
+// It returns false for reads, exceptions and non-write function codes.
+// FC22 carries masks, not a value: masked is the value the write stored.
+func appliedWrite(req *Request, pdu []byte, masked uint16) (AppliedWrite, bool) {
+	if len(pdu) == 0 || pdu[0] != req.FunctionCode {
+		return AppliedWrite{}, false
+	}
+
+	w := AppliedWrite{
+		MemoryID:     memorycore.MemoryID{Port: req.Port, UnitID: uint16(req.UnitID)},
+		FunctionCode: req.FunctionCode,
+	}
+
+	switch req.FunctionCode {
+	case 5:
+		d, err := DecodeWriteSingle(req.Payload)
+		if err != nil {
+			return AppliedWrite{}, false
+		}
+		var bit byte
+		if d.Value == 0xFF00 {
+			bit = 1
+		}
+		w.Area, w.Address, w.Count, w.Src = memorycore.AreaCoils, d.Address, 1, []byte{bit}
+
+	case 6:
+		d, err := DecodeWriteSingle(req.Payload)
+		if err != nil {
+			return AppliedWrite{}, false
+		}
+		w.Area, w.Address, w.Count = memorycore.AreaHoldingRegs, d.Address, 1
+		w.Src = binary.BigEndian.AppendUint16(nil, d.Value)
+
+	case 15:
+		d, err := DecodeWriteMultipleBits(req.Payload)
+		if err != nil {
+			return AppliedWrite{}, false
+		}
+		w.Area, w.Address, w.Count, w.Src = memorycore.AreaCoils, d.Address, d.Quantity, d.Data
+
+	case 16:
+		d, err := DecodeWriteMultiple(req.Payload)
+		if err != nil {
+			return AppliedWrite{}, false
+		}
+		w.Area, w.Address, w.Count = memorycore.AreaHoldingRegs, d.Address, d.Quantity
+		w.Src = make([]byte, 0, len(d.Values)*2)
+		for _, v := range d.Values {
+			w.Src = binary.BigEndian.AppendUint16(w.Src, v)
+		}
+
+	case 22:
+		d, err := DecodeMaskWriteReg(req.Payload)
+		if err != nil {
+			return AppliedWrite{}, false
+		}
+		w.Area, w.Address, w.Count = memorycore.AreaHoldingRegs, d.Address, 1
+		w.Src = binary.BigEndian.AppendUint16(nil, masked)
+
+	case 23:
+		d, err := DecodeReadWriteMultiple(req.Payload)
+		if err != nil {
+			return AppliedWrite{}, false
+		}
+		w.Area, w.Address, w.Count = memorycore.AreaHoldingRegs, d.WriteAddress, d.WriteQuantity
+		w.Src = make([]byte, 0, len(d.Values)*2)
+		for _, v := range d.Values {
+			w.Src = binary.BigEndian.AppendUint16(w.Src, v)
+		}
+
+	default:
+		return AppliedWrite{}, false
+	}
+
+	return w, true
+}
@@ -0,0 +1,56 @@
+// internal/transport/modbus/write_observer_test.go
+package modbus
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"MMA2.0/internal/memorycore"
+)
+
+func TestAppliedWriteMaskWriteReg(t *testing.T) {
+	mem, err := memorycore.NewMemory(memorycore.MemoryLayouts{
+		HoldingRegs: &memorycore.AreaLayout{Start: 0, Size: 8},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mid := memorycore.MemoryID{Port: 502, UnitID: 1}
+	store := memorycore.NewStore()
+	if err := store.Add(mid, mem); err != nil {
+		t.Fatal(err)
+	}
+	if err := mem.WriteRegs(memorycore.AreaHoldingRegs, 4, 1, []byte{0x00, 0x12}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Modbus spec example: 0x0012 AND 0x00F2 OR 0x0025 = 0x0017.
+	req := &Request{Port: 502, UnitID: 1, FunctionCode: 22, Payload: []byte{0, 4, 0x00, 0xF2, 0x00, 0x25}}
+	pdu, masked := dispatchLocal(store, req)
+	if masked != 0x0017 {
+		t.Fatalf("masked = %#04x, want 0x0017", masked)
+	}
+
+	// A later write must not leak into the observed value.
+	if err := mem.WriteRegs(memorycore.AreaHoldingRegs, 4, 1, []byte{0xFF, 0xFF}); err != nil {
+		t.Fatal(err)
+	}
+
+	w, ok := appliedWrite(req, pdu, masked)
+	if !ok {
+		t.Fatal("FC22 not reported as a write")
+	}
+	if w.Area != memorycore.AreaHoldingRegs || w.Address != 4 || w.Count != 1 {
+		t.Fatalf("range = %v %d+%d, want holding 4+1", w.Area, w.Address, w.Count)
+	}
+	if got := binary.BigEndian.Uint16(w.Src); got != 0x0017 {
+		t.Fatalf("observed value = %#04x, want 0x0017", got)
+	}
+
+	// Exceptions are not writes.
+	req = &Request{Port: 502, UnitID: 1, FunctionCode: 22, Payload: []byte{0, 9, 0, 0, 0, 0}}
+	pdu, masked = dispatchLocal(store, req)
+	if _, ok := appliedWrite(req, pdu, masked); ok {
+		t.Fatal("exception reported as a write")
+	}
+}
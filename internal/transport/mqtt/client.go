@@ -0,0 +1,402 @@
+// internal/transport/mqtt/client.go
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"MMA2.0/internal/authority"
+	"MMA2.0/internal/ingest"
+	"MMA2.0/internal/memorycore"
+	"MMA2.0/internal/transport/rawingest"
+)
+
+// MQTT is a write-only ingest transport (docs/06_TRANSPORTS.md).
+// The client subscribes and applies messages; it never publishes.
+// Targets come from the payload only, never from the topic.
+
+// Format selects how a subscription's payloads are decoded.
+type Format uint8
+
+const (
+	// FormatJSON: ingest.WriteRequest (explicit port/unit_id/area/address/values).
+	FormatJSON Format = iota
+
+	// FormatRaw: one raw ingest packet (v1, v2 or batch).
+	// The packet carries unit/area/address; the port comes from the subscription.
+	FormatRaw
+)
+
+// Defaults.
+const (
+	DefaultKeepAlive      = 30 * time.Second
+	DefaultMinBackoff     = time.Second
+	DefaultMaxBackoff     = time.Minute
+	DefaultMaxPacketBytes = 1 << 20
+
+	// connectTimeout bounds dial + CONNACK + SUBACK.
+	connectTimeout = 10 * time.Second
+)
+
+// Subscription is one topic filter and its payload format.
+type Subscription struct {
+	Topic  string
+	QoS    byte // 0 or 1
+	Format Format
+	Port   uint16 // FormatRaw only
+}
+
+// Options configures one broker connection.
+type Options struct {
+	ID       string
+	Broker   string // host:port
+	ClientID string
+	Username string
+	Password string
+
+	KeepAlive    time.Duration
+	CleanSession bool
+
+	Subscriptions []Subscription
+
+	MaxPacketBytes int
+	MinBackoff     time.Duration
+	MaxBackoff     time.Duration
+
+	// Dial opens the broker connection (nil = TCP).
+	// Injectable so the client can run against an in-process broker.
+	Dial func(network, addr string) (net.Conn, error)
+}
+
+// Stats are the message counters of one client.
+type Stats struct {
+	Received uint64
+	Applied  uint64
+	Rejected uint64
+}
+
+// Client is an MQTT 3.1.1 ingest client for one broker.
+type Client struct {
+	opts  Options
+	store *memorycore.Store
+	auth  *authority.Authority
+
+	done     chan struct{}
+	stopOnce sync.Once
+
+	received atomic.Uint64
+	applied  atomic.Uint64
+	rejected atomic.Uint64
+}
+
+// NewClient creates a client. Zero option values select defaults.
+func NewClient(opts Options, store *memorycore.Store, auth *authority.Authority) *Client {
+	if opts.KeepAlive <= 0 {
+		opts.KeepAlive = DefaultKeepAlive
+	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = DefaultMinBackoff
+	}
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = DefaultMaxBackoff
+	}
+	if opts.MaxPacketBytes <= 0 {
+		opts.MaxPacketBytes = DefaultMaxPacketBytes
+	}
+	if opts.Dial == nil {
+		opts.Dial = func(network, addr string) (net.Conn, error) {
+			return net.DialTimeout(network, addr, connectTimeout)
+		}
+	}
+
+	return &Client{
+		opts:  opts,
+		store: store,
+		auth:  auth,
+		done:  make(chan struct{}),
+	}
+}
+
+// Stats returns the message counters.
+func (c *Client) Stats() Stats {
+	return Stats{
+		Received: c.received.Load(),
+		Applied:  c.applied.Load(),
+		Rejected: c.rejected.Load(),
+	}
+}
+
+// Stop ends Run and closes the current connection.
+func (c *Client) Stop() {
+	c.stopOnce.Do(func() { close(c.done) })
+}
+
+// Run connects and serves until Stop is called.
+// Connection failures are retried with exponential backoff, reset after
+// every successful session setup (CONNACK + SUBACK).
+func (c *Client) Run() {
+	backoff := c.opts.MinBackoff
+
+	for {
+		established, err := c.session()
+
+		select {
+		case <-c.done:
+			return
+		default:
+		}
+
+		if established {
+			backoff = c.opts.MinBackoff
+		}
+		log.Printf("mqtt %s: disconnected from %s: %v (retry in %s)", c.opts.ID, c.opts.Broker, err, backoff)
+
+		select {
+		case <-c.done:
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > c.opts.MaxBackoff {
+			backoff = c.opts.MaxBackoff
+		}
+	}
+}
+
+// session runs one connection lifetime.
+// established reports whether CONNACK and SUBACK were received.
+func (c *Client) session() (established bool, err error) {
+	conn, err := c.opts.Dial("tcp", c.opts.Broker)
+	if err != nil {
+		return false, err
+	}
+
+	var wmu sync.Mutex
+	write := func(b []byte) error {
+		wmu.Lock()
+		defer wmu.Unlock()
+		_, err := conn.Write(b)
+		return err
+	}
+
+	closed := make(chan struct{})
+	defer close(closed)
+
+	// Stop closes the connection to unblock reads.
+	go func() {
+		select {
+		case <-c.done:
+			_ = conn.SetWriteDeadline(time.Now().Add(time.Second))
+			_ = write(encodeDisconnect())
+			conn.Close()
+		case <-closed:
+			conn.Close()
+		}
+	}()
+
+	r := bufio.NewReader(conn)
+	src := connSource(conn)
+
+	// --------------------
+	// CONNECT / CONNACK
+	// --------------------
+	_ = conn.SetDeadline(time.Now().Add(connectTimeout))
+
+	keepAlive := uint16(min(c.opts.KeepAlive/time.Second, 0xFFFF))
+	if err := write(encodeConnect(c.opts.ClientID, c.opts.Username, c.opts.Password, keepAlive, c.opts.CleanSession)); err != nil {
+		return false, err
+	}
+
+	p, err := readPacket(r, c.opts.MaxPacketBytes)
+	if err != nil {
+		return false, err
+	}
+	if err := decodeConnack(p); err != nil {
+		return false, err
+	}
+
+	// --------------------
+	// SUBSCRIBE / SUBACK
+	// A persistent session may deliver PUBLISH before SUBACK.
+	// --------------------
+	const subID = 1
+
+	topics := make([]string, len(c.opts.Subscriptions))
+	qos := make([]byte, len(c.opts.Subscriptions))
+	for i, s := range c.opts.Subscriptions {
+		topics[i], qos[i] = s.Topic, s.QoS
+	}
+	if err := write(encodeSubscribe(subID, topics, qos)); err != nil {
+		return false, err
+	}
+
+	for {
+		p, err := readPacket(r, c.opts.MaxPacketBytes)
+		if err != nil {
+			return false, err
+		}
+		if p.typ == typePublish {
+			if err := c.handlePublish(p, src, write); err != nil {
+				return false, err
+			}
+			continue
+		}
+		if err := decodeSuback(p, subID, len(topics)); err != nil {
+			return false, err
+		}
+		break
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+	log.Printf("mqtt %s: connected to %s (%d subscriptions)", c.opts.ID, c.opts.Broker, len(topics))
+
+	// --------------------
+	// Keep-alive
+	// --------------------
+	go func() {
+		t := time.NewTicker(c.opts.KeepAlive / 2)
+		defer t.Stop()
+		for {
+			select {
+			case <-closed:
+				return
+			case <-t.C:
+				if err := write(encodePingreq()); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	// --------------------
+	// Receive loop
+	// --------------------
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(c.opts.KeepAlive * 3 / 2))
+
+		p, err := readPacket(r, c.opts.MaxPacketBytes)
+		if err != nil {
+			return true, err
+		}
+
+		switch p.typ {
+		case typePublish:
+			if err := c.handlePublish(p, src, write); err != nil {
+				return true, err
+			}
+		case typePingresp:
+		default:
+			return true, fmt.Errorf("%w: type %d", ErrUnexpected, p.typ)
+		}
+	}
+}
+
+// handlePublish applies one message and acknowledges QoS 1.
+//
+// PUBACK is sent after the write, whether it was applied or rejected:
+// a rejection is final and redelivery would only repeat it.
+func (c *Client) handlePublish(p packet, src netip.Addr, write func([]byte) error) error {
+	msg, err := decodePublish(p)
+	if err != nil {
+		return err
+	}
+
+	c.received.Add(1)
+
+	if err := c.apply(msg, src); err != nil {
+		n := c.rejected.Add(1)
+		log.Printf("mqtt %s: rejected message on %q: %v [rejected=%d]", c.opts.ID, msg.topic, err, n)
+	} else {
+		c.applied.Add(1)
+	}
+
+	if msg.qos == 1 {
+		return write(encodePuback(msg.packetID))
+	}
+	return nil
+}
+
+// apply decodes one payload per its subscription format and writes it.
+func (c *Client) apply(msg publish, src netip.Addr) error {
+	sub, ok := c.match(msg.topic)
+	if !ok {
+		return fmt.Errorf("no subscription matches topic")
+	}
+
+	switch sub.Format {
+	case FormatJSON:
+		req, err := ingest.DecodeWriteRequest(msg.payload)
+		if err != nil {
+			return err
+		}
+		mid, writes, err := req.Resolve()
+		if err != nil {
+			return err
+		}
+		return ingest.Apply(c.store, c.auth, ingest.Source{IP: src}, mid, writes)
+
+	case FormatRaw:
+		r := bytes.NewReader(msg.payload)
+		pkt, err := rawingest.DecodeOne(r, sub.Port)
+		if err != nil {
+			return err
+		}
+		if pkt.Version == rawingest.VersionPipeline || r.Len() != 0 {
+			return rawingest.ErrBadFrame
+		}
+		return rawingest.Apply(c.store, c.auth, src, pkt)
+
+	default:
+		return errors.New("unknown payload format")
+	}
+}
+
+// match returns the first subscription whose filter matches topic.
+func (c *Client) match(topic string) (Subscription, bool) {
+	for _, s := range c.opts.Subscriptions {
+		if TopicMatch(s.Topic, topic) {
+			return s, true
+		}
+	}
+	return Subscription{}, false
+}
+
+// TopicMatch reports whether an MQTT topic filter matches a topic name.
+// '+' matches one level, a trailing '#' matches any remaining levels.
+func TopicMatch(filter, topic string) bool {
+	f := strings.Split(filter, "/")
+	t := strings.Split(topic, "/")
+
+	for i, level := range f {
+		if level == "#" {
+			return true
+		}
+		if i >= len(t) {
+			return false
+		}
+		if level != "+" && level != t[i] {
+			return false
+		}
+	}
+	return len(f) == len(t)
+}
+
+// connSource extracts the broker IP (the ingest policy source).
+func connSource(conn net.Conn) netip.Addr {
+	a, ok := conn.RemoteAddr().(*net.TCPAddr)
+	if !ok {
+		return netip.Addr{}
+	}
+	ip, _ := netip.AddrFromSlice(a.IP)
+	return ip.Unmap()
+}
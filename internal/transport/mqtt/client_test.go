@@ -0,0 +1,400 @@
+// internal/transport/mqtt/client_test.go
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"MMA2.0/internal/authority"
+	"MMA2.0/internal/memorycore"
+	"MMA2.0/internal/transport/rawingest"
+)
+
+// fakeBroker is an in-process broker reached through Options.Dial.
+// Each dial yields a net.Pipe whose server side is handed to the test.
+type fakeBroker struct {
+	conns chan *brokerConn
+
+	mu    sync.Mutex
+	dials []time.Time
+	fail  int // remaining dials to refuse
+}
+
+func newFakeBroker() *fakeBroker {
+	return &fakeBroker{conns: make(chan *brokerConn, 16)}
+}
+
+func (b *fakeBroker) dial(network, addr string) (net.Conn, error) {
+	b.mu.Lock()
+	b.dials = append(b.dials, time.Now())
+	refuse := b.fail > 0
+	if refuse {
+		b.fail--
+	}
+	b.mu.Unlock()
+
+	if refuse {
+		return nil, errors.New("connection refused")
+	}
+
+	client, server := net.Pipe()
+	b.conns <- &brokerConn{conn: server, r: bufio.NewReader(server)}
+	return client, nil
+}
+
+func (b *fakeBroker) dialTimes() []time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]time.Time(nil), b.dials...)
+}
+
+// accept waits for the next client connection.
+func (b *fakeBroker) accept(t *testing.T) *brokerConn {
+	t.Helper()
+	select {
+	case c := <-b.conns:
+		t.Cleanup(func() { c.conn.Close() })
+		return c
+	case <-time.After(5 * time.Second):
+		t.Fatal("no connection from client")
+		return nil
+	}
+}
+
+// brokerConn is the broker side of one client connection.
+type brokerConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func (c *brokerConn) expect(t *testing.T, typ byte) packet {
+	t.Helper()
+	_ = c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	p, err := readPacket(c.r, DefaultMaxPacketBytes)
+	if err != nil {
+		t.Fatalf("broker read: %v", err)
+	}
+	if p.typ != typ {
+		t.Fatalf("broker got packet type %d, want %d", p.typ, typ)
+	}
+	return p
+}
+
+func (c *brokerConn) send(t *testing.T, b []byte) {
+	t.Helper()
+	_ = c.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	if _, err := c.conn.Write(b); err != nil {
+		t.Fatalf("broker write: %v", err)
+	}
+}
+
+// handshake answers CONNECT and SUBSCRIBE with success.
+func (c *brokerConn) handshake(t *testing.T) {
+	t.Helper()
+	c.expect(t, typeConnect)
+	c.send(t, encode(typeConnack, 0, []byte{0, 0}))
+
+	sub := c.expect(t, typeSubscribe)
+	c.send(t, suback(sub))
+}
+
+// suback grants every topic of a SUBSCRIBE at its requested QoS.
+func suback(sub packet) []byte {
+	body := append([]byte(nil), sub.body[0:2]...)
+	b := sub.body[2:]
+	for len(b) > 0 {
+		n := int(binary.BigEndian.Uint16(b[0:2]))
+		body = append(body, b[2+n])
+		b = b[3+n:]
+	}
+	return encode(typeSuback, 0, body)
+}
+
+func encodePublish(topic string, qos byte, packetID uint16, payload []byte) []byte {
+	body := appendString(nil, topic)
+	if qos > 0 {
+		body = binary.BigEndian.AppendUint16(body, packetID)
+	}
+	body = append(body, payload...)
+	return encode(typePublish, qos<<1, body)
+}
+
+func newTestStore(t *testing.T, mid memorycore.MemoryID) (*memorycore.Store, *memorycore.Memory) {
+	t.Helper()
+	mem, err := memorycore.NewMemory(memorycore.MemoryLayouts{
+		Coils:       &memorycore.AreaLayout{Start: 0, Size: 16},
+		HoldingRegs: &memorycore.AreaLayout{Start: 0, Size: 16},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := memorycore.NewStore()
+	if err := store.Add(mid, mem); err != nil {
+		t.Fatal(err)
+	}
+	return store, mem
+}
+
+func startClient(t *testing.T, opts Options, store *memorycore.Store) (*Client, *fakeBroker) {
+	t.Helper()
+	b := newFakeBroker()
+	opts.Broker = "broker:1883"
+	opts.Dial = b.dial
+	if opts.ClientID == "" {
+		opts.ClientID = "test"
+	}
+
+	c := NewClient(opts, store, authority.New())
+	done := make(chan struct{})
+	go func() {
+		c.Run()
+		close(done)
+	}()
+	t.Cleanup(func() {
+		c.Stop()
+		<-done
+	})
+	return c, b
+}
+
+func readHolding(t *testing.T, mem *memorycore.Memory, address, count uint16) []uint16 {
+	t.Helper()
+	buf := make([]byte, 2*count)
+	if err := mem.ReadRegs(memorycore.AreaHoldingRegs, address, count, buf); err != nil {
+		t.Fatal(err)
+	}
+	out := make([]uint16, count)
+	for i := range out {
+		out[i] = binary.BigEndian.Uint16(buf[2*i:])
+	}
+	return out
+}
+
+func TestConnectSubscribe(t *testing.T) {
+	store, _ := newTestStore(t, memorycore.MemoryID{Port: 502, UnitID: 1})
+	_, b := startClient(t, Options{
+		ClientID:     "gw-1",
+		Username:     "user",
+		Password:     "secret",
+		KeepAlive:    45 * time.Second,
+		CleanSession: true,
+		Subscriptions: []Subscription{
+			{Topic: "plant/+/write", QoS: 1, Format: FormatJSON},
+			{Topic: "raw/#", QoS: 0, Format: FormatRaw, Port: 502},
+		},
+	}, store)
+
+	c := b.accept(t)
+
+	// CONNECT: "MQTT", level 4, flags, keep-alive, client id, user, password.
+	p := c.expect(t, typeConnect)
+	want := appendString(nil, "MQTT")
+	want = append(want, protocolLevel, connFlagUsername|connFlagPassword|connFlagCleanSession, 0, 45)
+	want = appendString(want, "gw-1")
+	want = appendString(want, "user")
+	want = appendString(want, "secret")
+	if string(p.body) != string(want) {
+		t.Fatalf("CONNECT body = % x, want % x", p.body, want)
+	}
+	c.send(t, encode(typeConnack, 0, []byte{0, 0}))
+
+	// SUBSCRIBE: fixed header flags 0b0010, packet id, filters with QoS.
+	sub := c.expect(t, typeSubscribe)
+	if sub.flags != 0x02 {
+		t.Fatalf("SUBSCRIBE flags = %#x, want 0x02", sub.flags)
+	}
+	want = binary.BigEndian.AppendUint16(nil, 1)
+	want = appendString(want, "plant/+/write")
+	want = append(want, 1)
+	want = appendString(want, "raw/#")
+	want = append(want, 0)
+	if string(sub.body) != string(want) {
+		t.Fatalf("SUBSCRIBE body = % x, want % x", sub.body, want)
+	}
+	c.send(t, suback(sub))
+}
+
+func TestConnackRefused(t *testing.T) {
+	store, _ := newTestStore(t, memorycore.MemoryID{Port: 502, UnitID: 1})
+	_, b := startClient(t, Options{
+		MinBackoff:    10 * time.Millisecond,
+		MaxBackoff:    10 * time.Millisecond,
+		Subscriptions: []Subscription{{Topic: "t"}},
+	}, store)
+
+	// Not authorized: the client closes and dials again.
+	c := b.accept(t)
+	c.expect(t, typeConnect)
+	c.send(t, encode(typeConnack, 0, []byte{0, 5}))
+
+	b.accept(t).expect(t, typeConnect)
+}
+
+func TestSubackFailure(t *testing.T) {
+	store, _ := newTestStore(t, memorycore.MemoryID{Port: 502, UnitID: 1})
+	_, b := startClient(t, Options{
+		MinBackoff:    10 * time.Millisecond,
+		MaxBackoff:    10 * time.Millisecond,
+		Subscriptions: []Subscription{{Topic: "t"}},
+	}, store)
+
+	c := b.accept(t)
+	c.expect(t, typeConnect)
+	c.send(t, encode(typeConnack, 0, []byte{0, 0}))
+	sub := c.expect(t, typeSubscribe)
+	c.send(t, encode(typeSuback, 0, append(sub.body[0:2:2], subackFailure)))
+
+	b.accept(t).expect(t, typeConnect)
+}
+
+func TestPublishJSONAckAfterApply(t *testing.T) {
+	mid := memorycore.MemoryID{Port: 502, UnitID: 1}
+	store, mem := newTestStore(t, mid)
+	client, b := startClient(t, Options{
+		Subscriptions: []Subscription{{Topic: "plant/+/write", QoS: 1, Format: FormatJSON}},
+	}, store)
+
+	c := b.accept(t)
+	c.handshake(t)
+
+	payload := []byte(`{"port":502,"unit_id":1,"area":"holding_registers","address":2,"values":[17,4660]}`)
+	c.send(t, encodePublish("plant/a/write", 1, 7, payload))
+
+	// PUBACK is sent only after the write reached memory.
+	ack := c.expect(t, typePuback)
+	if id := binary.BigEndian.Uint16(ack.body); id != 7 {
+		t.Fatalf("PUBACK packet id = %d, want 7", id)
+	}
+	if got := readHolding(t, mem, 2, 2); got[0] != 17 || got[1] != 0x1234 {
+		t.Fatalf("holding[2:4] = %v, want [17 4660]", got)
+	}
+
+	// A rejected write is still acknowledged (rejection is final).
+	c.send(t, encodePublish("plant/a/write", 1, 8, []byte(`{"port":502,"unit_id":1,"area":"holding_registers","address":15,"values":[1,2]}`)))
+	ack = c.expect(t, typePuback)
+	if id := binary.BigEndian.Uint16(ack.body); id != 8 {
+		t.Fatalf("PUBACK packet id = %d, want 8", id)
+	}
+
+	if s := client.Stats(); s != (Stats{Received: 2, Applied: 1, Rejected: 1}) {
+		t.Fatalf("stats = %+v", s)
+	}
+}
+
+func TestPublishRaw(t *testing.T) {
+	mid := memorycore.MemoryID{Port: 1502, UnitID: 3}
+	store, mem := newTestStore(t, mid)
+	client, b := startClient(t, Options{
+		Subscriptions: []Subscription{{Topic: "raw/#", QoS: 1, Format: FormatRaw, Port: 1502}},
+	}, store)
+
+	c := b.accept(t)
+	c.handshake(t)
+
+	frame, err := rawingest.AppendV2(nil, 1, 3, memorycore.AreaHoldingRegs, 4, 1, []byte{0xBE, 0xEF})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.send(t, encodePublish("raw/unit3", 1, 1, frame))
+	c.expect(t, typePuback)
+
+	if got := readHolding(t, mem, 4, 1); got[0] != 0xBEEF {
+		t.Fatalf("holding[4] = %#x, want 0xbeef", got[0])
+	}
+
+	// Trailing bytes after the frame reject the whole message.
+	c.send(t, encodePublish("raw/unit3", 1, 2, append(frame, 0)))
+	c.expect(t, typePuback)
+
+	if s := client.Stats(); s != (Stats{Received: 2, Applied: 1, Rejected: 1}) {
+		t.Fatalf("stats = %+v", s)
+	}
+}
+
+func TestQoS0NoPuback(t *testing.T) {
+	mid := memorycore.MemoryID{Port: 502, UnitID: 1}
+	store, mem := newTestStore(t, mid)
+	_, b := startClient(t, Options{
+		Subscriptions: []Subscription{{Topic: "w", QoS: 0, Format: FormatJSON}},
+	}, store)
+
+	c := b.accept(t)
+	c.handshake(t)
+
+	c.send(t, encodePublish("w", 0, 0, []byte(`{"port":502,"unit_id":1,"area":"coils","address":1,"values":[true]}`)))
+
+	// Nothing is written back for QoS 0; the client only reads.
+	_ = c.conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if _, err := readPacket(c.r, DefaultMaxPacketBytes); err == nil {
+		t.Fatal("unexpected packet after QoS 0 PUBLISH")
+	}
+
+	bits := make([]byte, 1)
+	if err := mem.ReadBits(memorycore.AreaCoils, 1, 1, bits); err != nil {
+		t.Fatal(err)
+	}
+	if bits[0] != 1 {
+		t.Fatalf("coil 1 = %d, want 1", bits[0])
+	}
+}
+
+func TestReconnectBackoff(t *testing.T) {
+	const (
+		minBackoff = 10 * time.Millisecond
+		maxBackoff = 80 * time.Millisecond
+	)
+
+	store, _ := newTestStore(t, memorycore.MemoryID{Port: 502, UnitID: 1})
+	b := newFakeBroker()
+	b.fail = 5
+
+	c := NewClient(Options{
+		ID:            "t",
+		Broker:        "broker:1883",
+		ClientID:      "test",
+		MinBackoff:    minBackoff,
+		MaxBackoff:    maxBackoff,
+		Subscriptions: []Subscription{{Topic: "t"}},
+		Dial:          b.dial,
+	}, store, authority.New())
+	done := make(chan struct{})
+	go func() {
+		c.Run()
+		close(done)
+	}()
+	defer func() {
+		c.Stop()
+		<-done
+	}()
+
+	// Five refused dials, then an established session the broker closes.
+	conn := b.accept(t)
+	conn.handshake(t)
+	conn.conn.Close()
+
+	// The next dial follows after the reset (minimum) backoff.
+	b.accept(t).expect(t, typeConnect)
+
+	dials := b.dialTimes()
+	if len(dials) < 7 {
+		t.Fatalf("dials = %d, want >= 7", len(dials))
+	}
+
+	// Backoff doubles per failure and is capped at maxBackoff.
+	want := []time.Duration{minBackoff, 2 * minBackoff, 4 * minBackoff, maxBackoff, maxBackoff}
+	for i, w := range want {
+		if gap := dials[i+1].Sub(dials[i]); gap < w {
+			t.Errorf("gap %d = %s, want >= %s", i, gap, w)
+		}
+	}
+
+	// The successful session reset the backoff to minBackoff.
+	if gap := dials[6].Sub(dials[5]); gap >= maxBackoff {
+		t.Errorf("gap after established session = %s, want < %s", gap, maxBackoff)
+	}
+}
@@ -0,0 +1,235 @@
+// internal/transport/mqtt/packet.go
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// MQTT 3.1.1 control packet types (fixed header, high nibble).
+const (
+	typeConnect    = byte(1)
+	typeConnack    = byte(2)
+	typePublish    = byte(3)
+	typePuback     = byte(4)
+	typeSubscribe  = byte(8)
+	typeSuback     = byte(9)
+	typePingreq    = byte(12)
+	typePingresp   = byte(13)
+	typeDisconnect = byte(14)
+)
+
+// protocolLevel is the CONNECT protocol level of MQTT 3.1.1.
+const protocolLevel = byte(4)
+
+// CONNECT flags.
+const (
+	connFlagCleanSession = byte(0x02)
+	connFlagPassword     = byte(0x40)
+	connFlagUsername     = byte(0x80)
+)
+
+// subackFailure is the SUBACK return code for a refused subscription.
+const subackFailure = byte(0x80)
+
+var (
+	ErrMalformed    = errors.New("mqtt: malformed packet")
+	ErrTooLarge     = errors.New("mqtt: packet too large")
+	ErrUnexpected   = errors.New("mqtt: unexpected packet")
+	ErrConnRefused  = errors.New("mqtt: connection refused")
+	ErrSubscription = errors.New("mqtt: subscription refused")
+)
+
+// packet is one decoded control packet.
+type packet struct {
+	typ   byte
+	flags byte
+	body  []byte
+}
+
+// publish is a decoded PUBLISH packet.
+type publish struct {
+	topic    string
+	qos      byte
+	packetID uint16
+	payload  []byte
+}
+
+// readPacket reads one control packet, rejecting bodies above maxLen.
+func readPacket(r *bufio.Reader, maxLen int) (packet, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return packet{}, err
+	}
+
+	n, err := readRemainingLength(r)
+	if err != nil {
+		return packet{}, err
+	}
+	if n > maxLen {
+		return packet{}, fmt.Errorf("%w: %d > %d bytes", ErrTooLarge, n, maxLen)
+	}
+
+	body := make([]byte, n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return packet{}, err
+	}
+
+	return packet{typ: b >> 4, flags: b & 0x0F, body: body}, nil
+}
+
+func readRemainingLength(r *bufio.Reader) (int, error) {
+	n, mul := 0, 1
+	for i := 0; i < 4; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		n += int(b&0x7F) * mul
+		if b&0x80 == 0 {
+			return n, nil
+		}
+		mul *= 128
+	}
+	return 0, fmt.Errorf("%w: remaining length", ErrMalformed)
+}
+
+// encode serializes a control packet (fixed header + body).
+func encode(typ, flags byte, body []byte) []byte {
+	out := make([]byte, 0, 5+len(body))
+	out = append(out, typ<<4|flags&0x0F)
+
+	n := len(body)
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		out = append(out, b)
+		if n == 0 {
+			break
+		}
+	}
+
+	return append(out, body...)
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// encodeConnect builds a CONNECT packet.
+func encodeConnect(clientID, username, password string, keepAlive uint16, clean bool) []byte {
+	var flags byte
+	if clean {
+		flags |= connFlagCleanSession
+	}
+	if username != "" {
+		flags |= connFlagUsername
+	}
+	if password != "" {
+		flags |= connFlagPassword
+	}
+
+	body := appendString(nil, "MQTT")
+	body = append(body, protocolLevel, flags)
+	body = binary.BigEndian.AppendUint16(body, keepAlive)
+	body = appendString(body, clientID)
+	if username != "" {
+		body = appendString(body, username)
+	}
+	if password != "" {
+		body = appendString(body, password)
+	}
+
+	return encode(typeConnect, 0, body)
+}
+
+// decodeConnack checks a CONNACK body.
+func decodeConnack(p packet) error {
+	if p.typ != typeConnack {
+		return fmt.Errorf("%w: type %d, want CONNACK", ErrUnexpected, p.typ)
+	}
+	if len(p.body) != 2 {
+		return fmt.Errorf("%w: CONNACK length %d", ErrMalformed, len(p.body))
+	}
+	if rc := p.body[1]; rc != 0 {
+		return fmt.Errorf("%w: return code %d", ErrConnRefused, rc)
+	}
+	return nil
+}
+
+// encodeSubscribe builds a SUBSCRIBE packet (fixed header flags 0b0010).
+func encodeSubscribe(packetID uint16, topics []string, qos []byte) []byte {
+	body := binary.BigEndian.AppendUint16(nil, packetID)
+	for i, t := range topics {
+		body = appendString(body, t)
+		body = append(body, qos[i])
+	}
+	return encode(typeSubscribe, 0x02, body)
+}
+
+// decodeSuback checks a SUBACK for packetID and n granted subscriptions.
+func decodeSuback(p packet, packetID uint16, n int) error {
+	if p.typ != typeSuback {
+		return fmt.Errorf("%w: type %d, want SUBACK", ErrUnexpected, p.typ)
+	}
+	if len(p.body) != 2+n || binary.BigEndian.Uint16(p.body[0:2]) != packetID {
+		return fmt.Errorf("%w: SUBACK", ErrMalformed)
+	}
+	for i, rc := range p.body[2:] {
+		if rc == subackFailure {
+			return fmt.Errorf("%w: topic #%d", ErrSubscription, i)
+		}
+	}
+	return nil
+}
+
+// decodePublish parses a PUBLISH packet.
+// QoS 2 is never requested in SUBSCRIBE and is rejected as malformed.
+func decodePublish(p packet) (publish, error) {
+	qos := (p.flags >> 1) & 0x03
+	if qos > 1 {
+		return publish{}, fmt.Errorf("%w: PUBLISH QoS %d", ErrMalformed, qos)
+	}
+
+	b := p.body
+	if len(b) < 2 {
+		return publish{}, fmt.Errorf("%w: PUBLISH topic", ErrMalformed)
+	}
+	tl := int(binary.BigEndian.Uint16(b[0:2]))
+	if len(b) < 2+tl {
+		return publish{}, fmt.Errorf("%w: PUBLISH topic", ErrMalformed)
+	}
+
+	msg := publish{topic: string(b[2 : 2+tl]), qos: qos}
+	b = b[2+tl:]
+
+	if qos > 0 {
+		if len(b) < 2 {
+			return publish{}, fmt.Errorf("%w: PUBLISH packet id", ErrMalformed)
+		}
+		msg.packetID = binary.BigEndian.Uint16(b[0:2])
+		b = b[2:]
+	}
+
+	msg.payload = b
+	return msg, nil
+}
+
+func encodePuback(packetID uint16) []byte {
+	return encode(typePuback, 0, binary.BigEndian.AppendUint16(nil, packetID))
+}
+
+func encodePingreq() []byte {
+	return encode(typePingreq, 0, nil)
+}
+
+func encodeDisconnect() []byte {
+	return encode(typeDisconnect, 0, nil)
+}
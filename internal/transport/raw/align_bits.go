@@ -1,4 +1,4 @@
-// internal/rawingest/align_bits.go
+// internal/transport/raw/align_bits.go
 // PURPOSE: Align packed-bit payload into []bool.
 // ALLOWED: pure alignment logic
 // FORBIDDEN: sockets, memory access, side effects
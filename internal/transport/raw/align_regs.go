@@ -1,4 +1,4 @@
-// internal/rawingest/align_regs.go
+// internal/transport/raw/align_regs.go
 // PURPOSE: Align register payload into []uint16.
 // ALLOWED: pure alignment logic
 // FORBIDDEN: sockets, memory access, side effects
@@ -1,4 +1,4 @@
-// internal/rawingest/conn_reader.go
+// internal/transport/raw/conn_reader.go
 // PURPOSE: Read one complete Raw Ingest V1 frame from a stream (length-framed).
 // ALLOWED: io reads, framing, size guards
 // FORBIDDEN: memory access, logging, retries, semantics
@@ -17,13 +17,14 @@ type frame struct {
 }
 
 // readFrame reads exactly one frame:
-//   - reads fixed header (12 bytes)
+//   - reads fixed header (14 bytes)
 //   - parses header
 //   - computes payload length
 //   - reads payload exactly
 //
 // It rejects any mismatch or ambiguity.
 // maxPacketBytes is a hard cap for header+payload.
+// A clean EOF before the first header byte is returned as io.EOF.
 func readFrame(r *bufio.Reader, maxPacketBytes int) (frame, error) {
 	if maxPacketBytes <= RawHeaderV1Size {
 		return frame{}, ErrRejected
@@ -31,6 +32,9 @@ func readFrame(r *bufio.Reader, maxPacketBytes int) (frame, error) {
 
 	hdr := make([]byte, RawHeaderV1Size)
 	if err := readExact(r, hdr); err != nil {
+		if err == io.EOF {
+			return frame{}, io.EOF
+		}
 		return frame{}, ErrRejected
 	}
 
@@ -1,4 +1,4 @@
-// internal/rawingest/conn_writer.go
+// internal/transport/raw/conn_writer.go
 // PURPOSE: Write single-byte Raw Ingest responses.
 // ALLOWED: io writes only
 // FORBIDDEN: logging, retries, semantics
@@ -1,38 +1,15 @@
-// internal/rawingest/constants.go
+// internal/transport/raw/constants.go
 // PURPOSE: Raw Ingest wire-level constants only.
 // ALLOWED: constants, enums, fixed sizes
 // FORBIDDEN: logic, imports, side effects
 
 package raw
 
-// ---- Wire protocol constants (V1) ----
+// Magic, version and header size: see header_v1.go.
+// The Area byte is a memorycore.Area value (table selector, NOT Modbus FCs).
 
-// Magic bytes: "RI"
-const (
-	magic0 = 0x52
-	magic1 = 0x49
-)
-
-// Protocol version
-const (
-	protocolVersionV1 = 0x01
-)
-
-// ---- Memory areas (table selector, NOT Modbus FCs) ----
-type MemoryArea uint8
-
-const (
-	AreaCoils          MemoryArea = 0x01
-	AreaDiscreteInputs MemoryArea = 0x02
-	AreaHoldingRegs    MemoryArea = 0x03
-	AreaInputRegs      MemoryArea = 0x04
-)
-
-// ---- Fixed sizes ----
-const (
-	v1HeaderSize = 12
-	crcSize      = 4
-)
+// DefaultMaxPacketBytes caps header+payload when no limit is configured.
+const DefaultMaxPacketBytes = 64 * 1024
 
 // ---- Single-byte responses ----
 const (
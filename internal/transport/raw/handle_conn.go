@@ -0,0 +1,53 @@
+// internal/transport/raw/handle_conn.go
+package raw
+
+import (
+	"log"
+	"net"
+	"net/netip"
+
+	"MMA2.0/internal/authority"
+	"MMA2.0/internal/ingest"
+	"MMA2.0/internal/memorycore"
+)
+
+// HandleConn serves one A55A raw frame connection.
+//
+// Unlike rawingest, the target memory is NOT derived from the local port:
+// every frame carries an explicit (Port, UnitID) so one connection can
+// feed any memory in the store.
+//
+// Frames are authorized through auth (ingest policy) for the connection's
+// source IP. maxPacketBytes <= 0 selects DefaultMaxPacketBytes.
+// A non-nil targets restricts which memories frames may address.
+func HandleConn(
+	conn net.Conn,
+	store *memorycore.Store,
+	auth *authority.Authority,
+	maxPacketBytes int,
+	targets []memorycore.MemoryID,
+) {
+	defer conn.Close()
+
+	remoteAddr, ok := conn.RemoteAddr().(*net.TCPAddr)
+	if !ok {
+		log.Printf("raw: failed to get remote TCP address")
+		return
+	}
+
+	srcIP, ok := netip.AddrFromSlice(remoteAddr.IP)
+	if !ok {
+		log.Printf("raw: invalid source IP %s", remoteAddr.IP)
+		return
+	}
+
+	if maxPacketBytes <= 0 {
+		maxPacketBytes = DefaultMaxPacketBytes
+	}
+
+	resolver := NewStoreResolver(store, auth, ingest.Source{IP: srcIP.Unmap()}, targets)
+
+	if err := Run(conn, resolver, maxPacketBytes); err != nil {
+		log.Printf("raw: %s: %v", conn.RemoteAddr(), err)
+	}
+}
@@ -7,6 +7,8 @@ package raw
 
 import (
 	"encoding/binary"
+
+	"MMA2.0/internal/memorycore"
 )
 
 // =========================
@@ -37,7 +39,7 @@ type v1Header struct {
 	Version uint8
 	Flags   uint8
 
-	Area MemoryArea
+	Area memorycore.Area
 	Rsv  uint8
 
 	UnitID uint16
@@ -63,7 +65,7 @@ func parseV1Header(buf []byte) (v1Header, error) {
 		Version: buf[2],
 		Flags:   buf[3],
 
-		Area: MemoryArea(buf[4]),
+		Area: memorycore.Area(buf[4]),
 		Rsv:  buf[5],
 
 		UnitID: binary.BigEndian.Uint16(buf[6:8]),
@@ -82,14 +84,7 @@ func parseV1Header(buf []byte) (v1Header, error) {
 		return v1Header{}, ErrRejected
 	}
 
-	switch h.Area {
-	case AreaCoils,
-	AreaDiscreteInputs,
-	AreaHoldingRegs,
-	AreaInputRegs:
-
-		
-	default:
+	if !h.Area.IsBitArea() && !h.Area.IsRegArea() {
 		return v1Header{}, ErrRejected
 	}
 
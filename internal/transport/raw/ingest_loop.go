@@ -7,7 +7,11 @@ package raw
 
 import (
 	"bufio"
+	"errors"
+	"io"
 	"net"
+
+	"MMA2.0/internal/memorycore"
 )
 
 // Status bytes (locked)
@@ -18,20 +22,24 @@ const (
 
 // Run executes the Raw Ingest v1 loop on a single TCP connection.
 // It reads frames, applies writes, and replies with a 1-byte status.
+// A clean EOF between frames ends the loop with a nil error.
 func Run(conn net.Conn, resolver MemoryResolver, maxPacketBytes int) error {
 	r := bufio.NewReader(conn)
 
 	for {
 		// Read one complete frame (header + payload)
 		f, err := readFrame(r, maxPacketBytes)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
 		if err != nil {
 			// Malformed frame → reply ERROR and stop
 			_, _ = conn.Write([]byte{StatusError})
 			return err
 		}
 
-		// Resolve target memory by UnitID (locked terminology)
-		mem, ok := resolver.ResolveMemoryByID(f.Header.UnitID)
+		// Resolve target memory by explicit (Port, UnitID)
+		mem, ok := resolver.ResolveMemory(f.Header.Port, f.Header.UnitID)
 		if !ok {
 			_, _ = conn.Write([]byte{StatusError})
 			return ErrRejected
@@ -40,7 +48,7 @@ func Run(conn net.Conn, resolver MemoryResolver, maxPacketBytes int) error {
 		// Apply payload based on Area
 		switch f.Header.Area {
 
-		case AreaCoils:
+		case memorycore.AreaCoils:
 			values, err := alignBits(f.Payload, f.Header.Count)
 			if err != nil {
 				_, _ = conn.Write([]byte{StatusError})
@@ -51,7 +59,7 @@ func Run(conn net.Conn, resolver MemoryResolver, maxPacketBytes int) error {
 				return ErrRejected
 			}
 
-		case AreaDiscreteInputs:
+		case memorycore.AreaDiscreteInputs:
 			values, err := alignBits(f.Payload, f.Header.Count)
 			if err != nil {
 				_, _ = conn.Write([]byte{StatusError})
@@ -62,7 +70,7 @@ func Run(conn net.Conn, resolver MemoryResolver, maxPacketBytes int) error {
 				return ErrRejected
 			}
 
-		case AreaHoldingRegs:
+		case memorycore.AreaHoldingRegs:
 			values, err := alignRegs(f.Payload, f.Header.Count)
 			if err != nil {
 				_, _ = conn.Write([]byte{StatusError})
@@ -73,7 +81,7 @@ func Run(conn net.Conn, resolver MemoryResolver, maxPacketBytes int) error {
 				return ErrRejected
 			}
 
-		case AreaInputRegs:
+		case memorycore.AreaInputRegs:
 			values, err := alignRegs(f.Payload, f.Header.Count)
 			if err != nil {
 				_, _ = conn.Write([]byte{StatusError})
@@ -84,6 +92,17 @@ func Run(conn net.Conn, resolver MemoryResolver, maxPacketBytes int) error {
 				return ErrRejected
 			}
 
+		case memorycore.AreaFileRecords:
+			values, err := alignRegs(f.Payload, f.Header.Count)
+			if err != nil {
+				_, _ = conn.Write([]byte{StatusError})
+				return ErrRejected
+			}
+			if err := mem.WriteFileRecords(f.Header.Address, values); err != nil {
+				_, _ = conn.Write([]byte{StatusError})
+				return ErrRejected
+			}
+
 		default:
 			// Should be unreachable due to header validation
 			_, _ = conn.Write([]byte{StatusError})
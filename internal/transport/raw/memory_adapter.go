@@ -1,47 +1,74 @@
-//go:build raw_ingest
-// +build raw_ingest
-
 // internal/transport/raw/memory_adapter.go
+// PURPOSE: Expose memorycore memory through RawWritableMemory.
+// ALLOWED: value packing, delegation to the shared ingest write path
+// FORBIDDEN: sockets, framing, logging
+
 package raw
 
-import "MMA2.0/internal/runtime"
+import (
+	"encoding/binary"
 
-// memoryAdapter exposes runtime memory through RawWritableMemory.
+	"MMA2.0/internal/authority"
+	"MMA2.0/internal/ingest"
+	"MMA2.0/internal/memorycore"
+)
+
+// memoryAdapter writes into one memorycore memory on behalf of one source.
+// Authorization, atomicity and state sealing are handled by ingest.Apply.
 type memoryAdapter struct {
-	mem *runtime.Memory
+	store *memorycore.Store
+	auth  *authority.Authority
+	src   ingest.Source
+	mid   memorycore.MemoryID
 }
 
 func (m *memoryAdapter) WriteCoils(addr uint16, values []bool) error {
-	for i, v := range values {
-		m.mem.Coils.Data[int(addr)+i] = boolToUint16(v)
-	}
-	return nil
+	return m.writeBits(memorycore.AreaCoils, addr, values)
 }
 
 func (m *memoryAdapter) WriteDiscreteInputs(addr uint16, values []bool) error {
-	for i, v := range values {
-		m.mem.DiscreteInputs.Data[int(addr)+i] = boolToUint16(v)
-	}
-	return nil
+	return m.writeBits(memorycore.AreaDiscreteInputs, addr, values)
 }
 
 func (m *memoryAdapter) WriteHoldingRegisters(addr uint16, values []uint16) error {
+	return m.writeRegs(memorycore.AreaHoldingRegs, addr, values)
+}
+
+func (m *memoryAdapter) WriteInputRegisters(addr uint16, values []uint16) error {
+	return m.writeRegs(memorycore.AreaInputRegs, addr, values)
+}
+
+func (m *memoryAdapter) WriteFileRecords(addr uint16, values []uint16) error {
+	return m.writeRegs(memorycore.AreaFileRecords, addr, values)
+}
+
+func (m *memoryAdapter) writeBits(area memorycore.Area, addr uint16, values []bool) error {
+	src := make([]byte, (len(values)+7)/8)
 	for i, v := range values {
-		m.mem.HoldingRegs.Data[int(addr)+i] = v
+		if v {
+			src[i/8] |= 1 << (i % 8)
+		}
 	}
-	return nil
+	return m.apply(area, addr, len(values), src)
 }
 
-func (m *memoryAdapter) WriteInputRegisters(addr uint16, values []uint16) error {
+func (m *memoryAdapter) writeRegs(area memorycore.Area, addr uint16, values []uint16) error {
+	src := make([]byte, len(values)*2)
 	for i, v := range values {
-		m.mem.InputRegs.Data[int(addr)+i] = v
+		binary.BigEndian.PutUint16(src[i*2:i*2+2], v)
 	}
-	return nil
+	return m.apply(area, addr, len(values), src)
 }
 
-func boolToUint16(v bool) uint16 {
-	if v {
-		return 1
+func (m *memoryAdapter) apply(area memorycore.Area, addr uint16, count int, src []byte) error {
+	if count == 0 || count > 0xFFFF {
+		return ErrRejected
 	}
-	return 0
+
+	return ingest.Apply(m.store, m.auth, m.src, m.mid, []memorycore.Write{{
+		Area:    area,
+		Address: addr,
+		Count:   uint16(count),
+		Src:     src,
+	}})
 }
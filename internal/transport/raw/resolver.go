@@ -1,27 +1,62 @@
-//go:build raw_ingest
-// +build raw_ingest
-
 // internal/transport/raw/resolver.go
+// PURPOSE: Resolve explicit (Port, UnitID) targets against memorycore.
+// ALLOWED: store lookup
+// FORBIDDEN: sockets, framing, logging
+
 package raw
 
-import "MMA2.0/internal/runtime"
+import (
+	"MMA2.0/internal/authority"
+	"MMA2.0/internal/ingest"
+	"MMA2.0/internal/memorycore"
+)
 
-// RuntimeResolver resolves memories from the MMA runtime.
-type RuntimeResolver struct {
-	rt *runtime.Runtime
+// StoreResolver resolves memories from the memorycore store.
+// It is bound to one connection source (ingest policy is per source).
+//
+// When targets is non-nil, only the listed memories resolve
+// (ingest listeners); nil means any memory in the store.
+type StoreResolver struct {
+	store   *memorycore.Store
+	auth    *authority.Authority
+	src     ingest.Source
+	targets map[memorycore.MemoryID]struct{}
 }
 
-func NewRuntimeResolver(rt *runtime.Runtime) *RuntimeResolver {
-	return &RuntimeResolver{rt: rt}
+func NewStoreResolver(
+	store *memorycore.Store,
+	auth *authority.Authority,
+	src ingest.Source,
+	targets []memorycore.MemoryID,
+) *StoreResolver {
+	r := &StoreResolver{store: store, auth: auth, src: src}
+
+	if targets != nil {
+		r.targets = make(map[memorycore.MemoryID]struct{}, len(targets))
+		for _, mid := range targets {
+			r.targets[mid] = struct{}{}
+		}
+	}
+
+	return r
 }
 
-// ResolveMemoryByID implements raw.MemoryResolver.
-func (r *RuntimeResolver) ResolveMemoryByID(id uint16) (RawWritableMemory, bool) {
-	for _, port := range r.rt.Ports {
-		unit := port.Units[uint8(id)]
-		if unit != nil {
-			return &memoryAdapter{mem: unit.Memory}, true
+// ResolveMemory implements raw.MemoryResolver.
+// The header's Port and UnitID form the MemoryID (no inference).
+func (r *StoreResolver) ResolveMemory(port uint16, unitID uint16) (RawWritableMemory, bool) {
+	mid := memorycore.MemoryID{Port: port, UnitID: unitID}
+	if r.targets != nil {
+		if _, ok := r.targets[mid]; !ok {
+			return nil, false
 		}
 	}
-	return nil, false
+	if _, ok := r.store.Get(mid); !ok {
+		return nil, false
+	}
+	return &memoryAdapter{
+		store: r.store,
+		auth:  r.auth,
+		src:   r.src,
+		mid:   mid,
+	}, true
 }
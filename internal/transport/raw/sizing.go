@@ -1,20 +1,22 @@
-// internal/rawingest/sizing.go
+// internal/transport/raw/sizing.go
 // PURPOSE: Compute payload size based on memory area and count.
 // ALLOWED: arithmetic only
 // FORBIDDEN: packet parsing, sockets, memory access
 
 package raw
 
+import "MMA2.0/internal/memorycore"
+
 // payloadSize returns the expected payload size in bytes
 // for a given memory area and element count.
-func payloadSize(area MemoryArea, count uint16) (int, error) {
-	switch area {
-	case AreaCoils, AreaDiscreteInputs:
+func payloadSize(area memorycore.Area, count uint16) (int, error) {
+	switch {
+	case area.IsBitArea():
 		// Packed bits, LSB-first, ceil(count / 8)
 		return int((count + 7) / 8), nil
 
-	case AreaHoldingRegs, AreaInputRegs:
-		// uint16 per register
+	case area.IsRegArea():
+		// uint16 per register (file records: flat word address)
 		return int(count) * 2, nil
 
 	default:
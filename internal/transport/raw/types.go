@@ -1,4 +1,4 @@
-// internal/rawingest/types.go
+// internal/transport/raw/types.go
 // PURPOSE: Raw Ingest interfaces only.
 // ALLOWED: interface and type definitions
 // FORBIDDEN: logic, imports, default implementations
@@ -12,10 +12,14 @@ type RawWritableMemory interface {
 	WriteDiscreteInputs(address uint16, values []bool) error
 	WriteHoldingRegisters(address uint16, values []uint16) error
 	WriteInputRegisters(address uint16, values []uint16) error
+
+	// WriteFileRecords writes the file-record area by flat word address
+	// ((file-1)*records + record), as every other ingest path does.
+	WriteFileRecords(address uint16, values []uint16) error
 }
 
-// MemoryResolver resolves a memory instance by numeric ID.
+// MemoryResolver resolves a memory instance by explicit (Port, UnitID).
 // Raw Ingest does not know how memories are created or routed.
 type MemoryResolver interface {
-	ResolveMemoryByID(id uint16) (RawWritableMemory, bool)
+	ResolveMemory(port uint16, unitID uint16) (RawWritableMemory, bool)
 }
@@ -0,0 +1,133 @@
+// internal/transport/rawingest/batch.go
+package rawingest
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+
+	"MMA2.0/internal/memorycore"
+)
+
+// Batch frame (VersionBatch) layout:
+//
+//	Header (14): Magic(2) Ver(1) Rsv(1) UnitID(2) N(2) Seq(4) Flags(1) Rsv(1)
+//	Entry  (N):  Area(1) Rsv(1) Address(2) Count(2) Payload
+//	Auth:        Nonce(8) Tag(32), only with FlagAuth (Tag covers port, unit_id, header, entries, nonce)
+//	Trailer:     CRC32(4) over everything before it
+//
+// All entries target the memory (Port, UnitID). They are applied under a
+// single memory lock and acknowledged once with a v2-style reply.
+const (
+	headerLenBatch = 14
+	entryHdrLen    = 6
+
+	// Hard caps (bounded memory per frame).
+	maxBatchEntries = 256
+	maxBatchPayload = 1 << 20
+)
+
+func decodeBatch(r io.Reader, port uint16, hdr []byte) (*Packet, error) {
+	seq := binary.BigEndian.Uint32(hdr[8:12])
+	fail := func(err error) (*Packet, error) {
+		return nil, &FrameError{Version: VersionBatch, Seq: seq, Err: err}
+	}
+	// failAligned: the whole frame was read and its CRC matched.
+	failAligned := func(err error) (*Packet, error) {
+		return nil, &FrameError{Version: VersionBatch, Seq: seq, Err: err, Aligned: true}
+	}
+
+	n := int(binary.BigEndian.Uint16(hdr[6:8]))
+	if n == 0 {
+		return fail(ErrCountZero)
+	}
+	if n > maxBatchEntries {
+		return fail(ErrBadFrame)
+	}
+
+	pkt := &Packet{
+		Version: VersionBatch,
+		Seq:     seq,
+		Flags:   hdr[12],
+		Port:    port,
+		UnitID:  binary.BigEndian.Uint16(hdr[4:6]),
+		Batch:   make([]memorycore.Write, 0, n),
+	}
+
+	signed := pkt.Flags&FlagAuth != 0
+	if signed {
+		pkt.Signed = append(make([]byte, 0, len(hdr)), hdr...)
+	}
+
+	crc := crc32.NewIEEE()
+	_, _ = crc.Write(hdr)
+
+	total := 0
+	var eh [entryHdrLen]byte
+
+	for i := 0; i < n; i++ {
+		if _, err := io.ReadFull(r, eh[:]); err != nil {
+			return nil, err
+		}
+		_, _ = crc.Write(eh[:])
+		if signed {
+			pkt.Signed = append(pkt.Signed, eh[:]...)
+		}
+
+		area := memorycore.Area(eh[0])
+		count := binary.BigEndian.Uint16(eh[4:6])
+
+		pl, err := payloadLen(area, count)
+		if err != nil {
+			return fail(err)
+		}
+
+		total += pl
+		if total > maxBatchPayload {
+			return fail(ErrBadFrame)
+		}
+
+		payload := make([]byte, pl)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return nil, err
+		}
+		_, _ = crc.Write(payload)
+		if signed {
+			pkt.Signed = append(pkt.Signed, payload...)
+		}
+
+		if eh[1] != 0 {
+			return fail(ErrBadFlags)
+		}
+
+		pkt.Batch = append(pkt.Batch, memorycore.Write{
+			Area:    area,
+			Address: binary.BigEndian.Uint16(eh[2:4]),
+			Count:   count,
+			Src:     payload,
+		})
+	}
+
+	if signed {
+		at := make([]byte, authTrailerLen)
+		if _, err := io.ReadFull(r, at); err != nil {
+			return nil, err
+		}
+		_, _ = crc.Write(at)
+		parseAuthTrailer(pkt, at)
+	}
+
+	var trailer [crcLen]byte
+	if _, err := io.ReadFull(r, trailer[:]); err != nil {
+		return nil, err
+	}
+	if crc.Sum32() != binary.BigEndian.Uint32(trailer[:]) {
+		return fail(ErrBadCRC)
+	}
+
+	if pkt.Flags&^flagsKnown != 0 || hdr[3] != 0 || hdr[13] != 0 {
+		return failAligned(ErrBadFlags)
+	}
+
+	return pkt, nil
+}
@@ -0,0 +1,108 @@
+// internal/transport/rawingest/batch_test.go
+package rawingest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"testing"
+
+	"MMA2.0/internal/memorycore"
+)
+
+// appendBatch builds an unauthenticated batch frame; Src must match Count.
+func appendBatch(dst []byte, seq uint32, unitID uint16, writes []memorycore.Write) []byte {
+	start := len(dst)
+	dst = append(dst, Magic0, Magic1, VersionBatch, 0)
+	dst = binary.BigEndian.AppendUint16(dst, unitID)
+	dst = binary.BigEndian.AppendUint16(dst, uint16(len(writes)))
+	dst = binary.BigEndian.AppendUint32(dst, seq)
+	dst = append(dst, 0, 0) // Flags, Rsv
+	for _, w := range writes {
+		dst = append(dst, byte(w.Area), 0)
+		dst = binary.BigEndian.AppendUint16(dst, w.Address)
+		dst = binary.BigEndian.AppendUint16(dst, w.Count)
+		dst = append(dst, w.Src...)
+	}
+	return binary.BigEndian.AppendUint32(dst, crc32.ChecksumIEEE(dst[start:]))
+}
+
+// regWrites returns one holding-register write per count.
+func regWrites(counts ...int) []memorycore.Write {
+	out := make([]memorycore.Write, len(counts))
+	for i, c := range counts {
+		out[i] = memorycore.Write{Area: memorycore.AreaHoldingRegs, Count: uint16(c), Src: make([]byte, c*2)}
+	}
+	return out
+}
+
+func repeat(n, count int) []int {
+	out := make([]int, n)
+	for i := range out {
+		out[i] = count
+	}
+	return out
+}
+
+func TestDecodeBatch(t *testing.T) {
+	writes := []memorycore.Write{
+		{Area: memorycore.AreaCoils, Address: 3, Count: 9, Src: []byte{0xFF, 0x01}},
+		{Area: memorycore.AreaHoldingRegs, Address: 10, Count: 2, Src: []byte{1, 2, 3, 4}},
+	}
+	pkt, err := DecodeOne(bytes.NewReader(appendBatch(nil, 7, 1, writes)), 502)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkt.Version != VersionBatch || pkt.Seq != 7 || pkt.Port != 502 || pkt.UnitID != 1 {
+		t.Fatalf("packet = %+v", pkt)
+	}
+	if len(pkt.Batch) != 2 {
+		t.Fatalf("entries = %d, want 2", len(pkt.Batch))
+	}
+	for i, w := range pkt.Batch {
+		if w.Area != writes[i].Area || w.Address != writes[i].Address || w.Count != writes[i].Count || !bytes.Equal(w.Src, writes[i].Src) {
+			t.Fatalf("entry %d = %+v, want %+v", i, w, writes[i])
+		}
+	}
+}
+
+func TestDecodeBatchCaps(t *testing.T) {
+	// 8 full entries (65535 registers each) leave 16 bytes below 1 MiB.
+	full := repeat(8, 0xFFFF)
+
+	for _, tc := range []struct {
+		name   string
+		counts []int
+		err    error
+	}{
+		{"max entries", repeat(maxBatchEntries, 1), nil},
+		{"too many entries", repeat(maxBatchEntries+1, 1), ErrBadFrame},
+		{"max payload", append(full, 8), nil},
+		{"payload over 1 MiB", append(full, 9), ErrBadFrame},
+		{"no entries", nil, ErrCountZero},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			frame := appendBatch(nil, 1, 1, regWrites(tc.counts...))
+
+			pkt, err := DecodeOne(bytes.NewReader(frame), 502)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("err = %v, want %v", err, tc.err)
+			}
+			if err == nil {
+				if len(pkt.Batch) != len(tc.counts) {
+					t.Fatalf("entries = %d, want %d", len(pkt.Batch), len(tc.counts))
+				}
+				return
+			}
+
+			var fe *FrameError
+			if !errors.As(err, &fe) || fe.Seq != 1 || fe.Aligned {
+				t.Fatalf("err = %#v, want an unaligned FrameError for seq 1", err)
+			}
+			if StatusFor(err) != StatusBadFrame {
+				t.Fatalf("status = 0x%02x, want 0x%02x", StatusFor(err), StatusBadFrame)
+			}
+		})
+	}
+}
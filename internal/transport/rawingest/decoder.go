@@ -3,17 +3,28 @@ package rawingest
 
 import (
 	"encoding/binary"
-	"fmt"
+	"hash/crc32"
 	"io"
 
+	"MMA2.0/internal/authority"
 	"MMA2.0/internal/memorycore"
 )
 
 const headerLen = 10 // Magic(2) Ver(1) Area(1) UnitID(2) Address(2) Count(2)
 
+// v2 header extends v1 with Seq(4) Flags(1) Rsv(1).
+// A CRC32 (IEEE) trailer over header+payload follows the payload.
+const (
+	headerLenV2 = headerLen + 6
+	crcLen      = 4
+
+	// FlagAuth trailer: Nonce(8) Tag(32)
+	authTrailerLen = 8 + authority.IngestTagSize
+)
+
 func payloadLen(area memorycore.Area, count uint16) (int, error) {
 	if count == 0 {
-		return 0, fmt.Errorf("count is zero")
+		return 0, ErrCountZero
 	}
 	if area.IsBitArea() {
 		return int((count + 7) / 8), nil
@@ -21,44 +32,128 @@ func payloadLen(area memorycore.Area, count uint16) (int, error) {
 	if area.IsRegArea() {
 		return int(count) * 2, nil
 	}
-	return 0, fmt.Errorf("invalid area")
+	return 0, ErrBadArea
 }
 
 // DecodeOne reads exactly one raw-ingest packet.
+// The version byte selects the frame layout:
+//
+//	v1: Magic(2) Ver(1) Area(1) UnitID(2) Address(2) Count(2) Payload
+//	v2: Magic(2) Ver(1) Area(1) UnitID(2) Address(2) Count(2) Seq(4) Flags(1) Rsv(1) Payload [Nonce(8) Tag(32)] CRC32(4)
+//	batch: see decodeBatch
+//	pipeline handshake: Magic(2) Ver(1) Rsv(1) Window(2) Rsv(4)
 func DecodeOne(r io.Reader, port uint16) (*Packet, error) {
-	var hdr [headerLen]byte
-	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+	var hdr [headerLenV2]byte
+	if _, err := io.ReadFull(r, hdr[:headerLen]); err != nil {
 		return nil, err
 	}
 
 	if hdr[0] != Magic0 || hdr[1] != Magic1 {
-		return nil, fmt.Errorf("bad magic")
+		return nil, ErrBadMagic
 	}
-	if hdr[2] != Version1 {
-		return nil, fmt.Errorf("bad version")
+
+	switch hdr[2] {
+	case Version1:
+		return decodeV1(r, port, hdr[:headerLen])
+	case Version2:
+		if _, err := io.ReadFull(r, hdr[headerLen:]); err != nil {
+			return nil, err
+		}
+		return decodeV2(r, port, hdr[:])
+	case VersionBatch:
+		if _, err := io.ReadFull(r, hdr[headerLen:headerLenBatch]); err != nil {
+			return nil, err
+		}
+		return decodeBatch(r, port, hdr[:headerLenBatch])
+	case VersionPipeline:
+		return decodeHandshake(hdr[:headerLen])
+	default:
+		return nil, ErrBadVersion
 	}
+}
 
-	area := memorycore.Area(hdr[3])
-	unitID := binary.BigEndian.Uint16(hdr[4:6])
-	addr := binary.BigEndian.Uint16(hdr[6:8])
-	count := binary.BigEndian.Uint16(hdr[8:10])
+func decodeHandshake(hdr []byte) (*Packet, error) {
+	if hdr[3] != 0 || binary.BigEndian.Uint32(hdr[6:10]) != 0 {
+		return nil, &FrameError{Version: VersionPipeline, Err: ErrBadFrame}
+	}
+	return &Packet{
+		Version: VersionPipeline,
+		Window:  binary.BigEndian.Uint16(hdr[4:6]),
+	}, nil
+}
 
-	n, err := payloadLen(area, count)
+func decodeV1(r io.Reader, port uint16, hdr []byte) (*Packet, error) {
+	pkt := parseCommonHeader(port, hdr)
+
+	n, err := payloadLen(pkt.Area, pkt.Count)
 	if err != nil {
 		return nil, err
 	}
 
-	payload := make([]byte, n)
-	if _, err := io.ReadFull(r, payload); err != nil {
+	pkt.Payload = make([]byte, n)
+	if _, err := io.ReadFull(r, pkt.Payload); err != nil {
 		return nil, err
 	}
 
+	return pkt, nil
+}
+
+func decodeV2(r io.Reader, port uint16, hdr []byte) (*Packet, error) {
+	pkt := parseCommonHeader(port, hdr)
+	pkt.Seq = binary.BigEndian.Uint32(hdr[10:14])
+	pkt.Flags = hdr[14]
+	rsv := hdr[15]
+
+	n, err := payloadLen(pkt.Area, pkt.Count)
+	if err != nil {
+		// Framing is unknown: the payload cannot be skipped.
+		return nil, &FrameError{Version: Version2, Seq: pkt.Seq, Err: err}
+	}
+
+	trailer := 0
+	if pkt.Flags&FlagAuth != 0 {
+		trailer = authTrailerLen
+	}
+
+	body := make([]byte, n+trailer+crcLen)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	pkt.Payload = body[:n]
+
+	crc := crc32.NewIEEE()
+	_, _ = crc.Write(hdr)
+	_, _ = crc.Write(body[:n+trailer])
+	if crc.Sum32() != binary.BigEndian.Uint32(body[n+trailer:]) {
+		return nil, &FrameError{Version: Version2, Seq: pkt.Seq, Err: ErrBadCRC}
+	}
+
+	if pkt.Flags&^flagsKnown != 0 || rsv != 0 {
+		return nil, &FrameError{Version: Version2, Seq: pkt.Seq, Err: ErrBadFlags, Aligned: true}
+	}
+
+	if trailer > 0 {
+		pkt.Signed = append(append(make([]byte, 0, len(hdr)+n), hdr...), pkt.Payload...)
+		parseAuthTrailer(pkt, body[n:n+trailer])
+	}
+
+	return pkt, nil
+}
+
+// parseAuthTrailer extracts Nonce(8) Tag(32).
+func parseAuthTrailer(pkt *Packet, b []byte) {
+	pkt.Nonce = binary.BigEndian.Uint64(b[0:8])
+	pkt.Tag = b[8:authTrailerLen]
+}
+
+// parseCommonHeader parses the fields shared by all versions (first 10 bytes).
+func parseCommonHeader(port uint16, hdr []byte) *Packet {
 	return &Packet{
+		Version: hdr[2],
 		Port:    port,
-		UnitID:  unitID,
-		Area:    area,
-		Address: addr,
-		Count:   count,
-		Payload: payload,
-	}, nil
+		Area:    memorycore.Area(hdr[3]),
+		UnitID:  binary.BigEndian.Uint16(hdr[4:6]),
+		Address: binary.BigEndian.Uint16(hdr[6:8]),
+		Count:   binary.BigEndian.Uint16(hdr[8:10]),
+	}
 }
@@ -0,0 +1,45 @@
+// internal/transport/rawingest/encoder.go
+package rawingest
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+
+	"MMA2.0/internal/memorycore"
+)
+
+// AppendV2 appends one unauthenticated v2 frame to dst (producer side).
+// payload must match the encoding described on Packet.Payload.
+func AppendV2(dst []byte, seq uint32, unitID uint16, area memorycore.Area, address, count uint16, payload []byte) ([]byte, error) {
+	n, err := payloadLen(area, count)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) != n {
+		return nil, ErrBadFrame
+	}
+
+	start := len(dst)
+	dst = append(dst, Magic0, Magic1, Version2, byte(area))
+	dst = binary.BigEndian.AppendUint16(dst, unitID)
+	dst = binary.BigEndian.AppendUint16(dst, address)
+	dst = binary.BigEndian.AppendUint16(dst, count)
+	dst = binary.BigEndian.AppendUint32(dst, seq)
+	dst = append(dst, 0, 0) // Flags, Rsv
+	dst = append(dst, payload...)
+
+	return binary.BigEndian.AppendUint32(dst, crc32.ChecksumIEEE(dst[start:])), nil
+}
+
+// ReadReplyV2 reads one 8-byte v2 reply and returns its status and sequence.
+func ReadReplyV2(r io.Reader) (status byte, seq uint32, err error) {
+	var b [replyLenV2]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, 0, err
+	}
+	if b[0] != Magic0 || b[1] != Magic1 || b[2] != Version2 {
+		return 0, 0, ErrBadMagic
+	}
+	return b[3], binary.BigEndian.Uint32(b[4:8]), nil
+}
@@ -0,0 +1,40 @@
+// internal/transport/rawingest/errors.go
+package rawingest
+
+import (
+	"errors"
+
+	"MMA2.0/internal/ingest"
+)
+
+var (
+	ErrBadMagic   = errors.New("bad magic")
+	ErrBadVersion = errors.New("bad version")
+	ErrBadFlags   = errors.New("bad flags")
+	ErrBadFrame   = errors.New("bad frame")
+	ErrBadCRC     = errors.New("crc mismatch")
+	ErrCountZero  = errors.New("count is zero")
+	ErrBadArea    = errors.New("invalid area")
+	ErrBadSeq     = errors.New("sequence not increasing")
+
+	// Shared with every ingest transport.
+	ErrNotAuthorized    = ingest.ErrNotAuthorized
+	ErrNotAuthenticated = ingest.ErrNotAuthenticated
+)
+
+// FrameError is a decode failure for a frame whose header was readable.
+// It carries enough identity (version, sequence) to reply precisely.
+type FrameError struct {
+	Version byte
+	Seq     uint32
+	Err     error
+
+	// Aligned: the frame passed its CRC and was consumed to its end, so
+	// the stream is still on a frame boundary and the connection can
+	// continue after replying (bad flags). A CRC mismatch is never
+	// aligned: the length came from header bytes that failed the check.
+	Aligned bool
+}
+
+func (e *FrameError) Error() string { return e.Err.Error() }
+func (e *FrameError) Unwrap() error { return e.Err }
@@ -2,18 +2,34 @@
 package rawingest
 
 import (
+	"bufio"
+	"errors"
 	"io"
 	"log"
 	"net"
+	"net/netip"
 
+	"MMA2.0/internal/authority"
+	"MMA2.0/internal/ingest"
 	"MMA2.0/internal/memorycore"
 )
 
 // HandleConn handles a single Raw Ingest TCP connection.
-// It writes exactly 1 byte per packet:
-//   0 = OK
-//   1 = REJECTED
-func HandleConn(conn net.Conn, store *memorycore.Store) {
+// It writes exactly one reply per packet, in the packet's wire version:
+//   v1: 1 byte (0 = OK, 1 = REJECTED)
+//   v2: 8 bytes (Magic, Ver, Status, echoed Seq)
+//
+// A frame with a good CRC but bad flags is answered with its status and
+// the connection continues. Other decode errors, a CRC mismatch included
+// (the frame length came from untrusted header bytes), are answered and
+// close the connection.
+//
+// A pipeline handshake as the first packet switches the connection to
+// windowed acknowledgements instead (see servePipelined).
+//
+// Every packet is authorized through auth (ingest policy) before it
+// touches memory. State sealing transitions are driven through auth.Sealing().
+func HandleConn(conn net.Conn, store *memorycore.Store, auth *authority.Authority) {
 	defer conn.Close()
 
 	localAddr, ok := conn.LocalAddr().(*net.TCPAddr)
@@ -23,38 +39,84 @@ func HandleConn(conn net.Conn, store *memorycore.Store) {
 	}
 	port := uint16(localAddr.Port)
 
-	for {
-		pkt, err := DecodeOne(conn, port)
+	// Extract remote source IP (ingest policy)
+	remoteAddr, ok := conn.RemoteAddr().(*net.TCPAddr)
+	if !ok {
+		log.Printf("rawingest: failed to get remote TCP address")
+		return
+	}
+
+	srcIP, err := netip.ParseAddr(remoteAddr.IP.String())
+	if err != nil {
+		log.Printf("rawingest: invalid source IP: %v", err)
+		return
+	}
+
+	r := bufio.NewReader(conn)
+
+	for first := true; ; first = false {
+		pkt, err := DecodeOne(r, port)
 		if err != nil {
 			if err != io.EOF {
-				_, _ = conn.Write([]byte{RespRejected})
+				var fe *FrameError
+				if errors.As(err, &fe) {
+					werr := writeReply(conn, fe.Version, fe.Seq, StatusFor(err))
+					if fe.Aligned && werr == nil {
+						log.Printf("rawingest: rejected frame seq=%d: %v", fe.Seq, err)
+						continue
+					}
+				} else {
+					_, _ = conn.Write([]byte{RespRejected})
+				}
 				log.Printf("rawingest decode error: %v", err)
 			}
 			return
 		}
 
-		memID := memorycore.MemoryID{Port: pkt.Port, UnitID: pkt.UnitID}
-		mem, err := store.MustGet(memID)
-		if err != nil {
-			_, _ = conn.Write([]byte{RespRejected})
-			continue
+		if pkt.Version == VersionPipeline {
+			if !first {
+				_ = writeReply(conn, VersionPipeline, 0, StatusBadFrame)
+				log.Printf("rawingest decode error: pipeline handshake after first packet")
+				return
+			}
+			servePipelined(conn, r, port, store, auth, srcIP, pkt.Window)
+			return
 		}
 
-		if pkt.Area.IsBitArea() {
-			if err := mem.WriteBits(pkt.Area, pkt.Address, pkt.Count, pkt.Payload); err != nil {
-				_, _ = conn.Write([]byte{RespRejected})
-				continue
-			}
-		} else if pkt.Area.IsRegArea() {
-			if err := mem.WriteRegs(pkt.Area, pkt.Address, pkt.Count, pkt.Payload); err != nil {
-				_, _ = conn.Write([]byte{RespRejected})
-				continue
-			}
-		} else {
-			_, _ = conn.Write([]byte{RespRejected})
-			continue
+		status := StatusFor(Apply(store, auth, srcIP, pkt))
+
+		if err := writeReply(conn, pkt.Version, pkt.Seq, status); err != nil {
+			log.Printf("rawingest write error: %v", err)
+			return
 		}
+	}
+}
 
-		_, _ = conn.Write([]byte{RespOK})
+// Apply authenticates, authorizes and writes one packet into memory.
+// Batch packets are all-or-nothing: one denied or invalid write
+// rejects the whole packet and memory is left unchanged.
+//
+// Authentication (FlagAuth) is checked first: unauthenticated or replayed
+// frames never reach memorycore. Rejections are logged.
+func Apply(store *memorycore.Store, auth *authority.Authority, srcIP netip.Addr, pkt *Packet) error {
+	err := apply(store, auth, srcIP, pkt)
+	if err != nil {
+		log.Printf("rawingest: rejected %s -> (port=%d unit=%d): %v", srcIP, pkt.Port, pkt.UnitID, err)
 	}
+	return err
+}
+
+// apply is Apply without logging (UDP reports through its counters).
+func apply(store *memorycore.Store, auth *authority.Authority, srcIP netip.Addr, pkt *Packet) error {
+	memID := memorycore.MemoryID{Port: pkt.Port, UnitID: pkt.UnitID}
+	src := ingest.Source{IP: srcIP}
+
+	if pkt.Flags&FlagAuth != 0 {
+		if err := auth.AuthenticateIngest(memID, pkt.Signed, pkt.Nonce, pkt.Tag); err != nil {
+			return err
+		}
+		src.Authenticated = true
+	}
+
+	return ingest.Apply(store, auth, src, memID, pkt.Writes())
 }
@@ -0,0 +1,93 @@
+// internal/transport/rawingest/handle_conn_test.go
+package rawingest
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"MMA2.0/internal/authority"
+	"MMA2.0/internal/memorycore"
+)
+
+// newRegStore serves (port, unit 1) with holding registers 0..3,
+// writable by ingest from any source.
+func newRegStore(t *testing.T, port uint16) (*memorycore.Store, *memorycore.Memory, *authority.Authority) {
+	t.Helper()
+	mem, err := memorycore.NewMemory(memorycore.MemoryLayouts{
+		HoldingRegs: &memorycore.AreaLayout{Start: 0, Size: 4},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mid := memorycore.MemoryID{Port: port, UnitID: 1}
+	store := memorycore.NewStore()
+	if err := store.Add(mid, mem); err != nil {
+		t.Fatal(err)
+	}
+	auth := authority.New()
+	auth.SetMemoryPolicy(mid, &authority.MemoryPolicy{})
+	return store, mem, auth
+}
+
+func TestHandleConnBadCRCCloses(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	store, mem, auth := newRegStore(t, uint16(ln.Addr().(*net.TCPAddr).Port))
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		HandleConn(conn, store, auth)
+	}()
+
+	c, err := net.DialTimeout("tcp", ln.Addr().String(), 2*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	_ = c.SetDeadline(time.Now().Add(2 * time.Second))
+
+	bad, err := AppendV2(nil, 1, 1, memorycore.AreaHoldingRegs, 0, 1, []byte{0xDE, 0xAD})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad[len(bad)-1] ^= 0xFF
+
+	good, err := AppendV2(nil, 2, 1, memorycore.AreaHoldingRegs, 1, 1, []byte{0x12, 0x34})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both frames in one write: after a CRC mismatch the frame boundary
+	// is unknown, so the server replies and closes without reading on.
+	if _, err := c.Write(append(bad, good...)); err != nil {
+		t.Fatal(err)
+	}
+
+	status, seq, err := ReadReplyV2(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seq != 1 || status != StatusBadCRC {
+		t.Fatalf("reply seq=%d status=0x%02x, want seq=1 status=0x%02x", seq, status, StatusBadCRC)
+	}
+	if n, err := c.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("after bad CRC: read n=%d err=%v, want EOF", n, err)
+	}
+
+	regs := make([]byte, 4)
+	if err := mem.ReadRegs(memorycore.AreaHoldingRegs, 0, 2, regs); err != nil {
+		t.Fatal(err)
+	}
+	if string(regs) != string(make([]byte, 4)) {
+		t.Fatalf("regs = % x, want zeros", regs)
+	}
+}
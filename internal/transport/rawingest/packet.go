@@ -5,6 +5,21 @@ import "MMA2.0/internal/memorycore"
 
 // Packet is a single raw-ingest write primitive.
 type Packet struct {
+	// Wire version the packet arrived with (selects the reply format).
+	Version byte
+
+	// v2+: producer sequence number, echoed in the reply.
+	Seq uint32
+
+	// v2+: header flags (see FlagAuth; other bits must be zero).
+	Flags byte
+
+	// FlagAuth only: authentication trailer and the bytes it covers
+	// (header + payload/entries, excluding the nonce itself).
+	Nonce  uint64
+	Tag    []byte
+	Signed []byte
+
 	Port   uint16
 	UnitID uint16
 
@@ -16,14 +31,58 @@ type Packet struct {
 	// - Bit areas: packed bits (LSB-first), bytes = ceil(count/8)
 	// - Reg areas: big-endian uint16 words, bytes = count*2
 	Payload []byte
+
+	// VersionBatch only: N writes applied all-or-nothing.
+	// Area/Address/Count/Payload are unused for batch packets.
+	Batch []memorycore.Write
+
+	// VersionPipeline only: requested acknowledgement window.
+	// A handshake packet carries no writes.
+	Window uint16
+}
+
+// Writes returns the packet as a list of memory writes.
+func (p *Packet) Writes() []memorycore.Write {
+	if p.Version == VersionBatch {
+		return p.Batch
+	}
+	return []memorycore.Write{{
+		Area:    p.Area,
+		Address: p.Address,
+		Count:   p.Count,
+		Src:     p.Payload,
+	}}
 }
 
 const (
 	Magic0 = byte('R')
 	Magic1 = byte('I')
 
+	// Version1: 1-byte reply, no integrity check.
 	Version1 = byte(0x01)
 
+	// Version2: sequence number, CRC32 trailer, 8-byte status reply.
+	Version2 = byte(0x02)
+
+	// VersionBatch: v2 framing carrying N writes to one memory,
+	// applied atomically and acknowledged once.
+	VersionBatch = byte(0x03)
+
+	// VersionPipeline: handshake switching the connection to pipelined
+	// mode (see servePipelined). It must be the first packet.
+	VersionPipeline = byte(0x04)
+
 	RespOK       = byte(0)
 	RespRejected = byte(1)
 )
+
+// Header flags (v2, batch).
+const (
+	// FlagAuth: an authentication trailer Nonce(8) Tag(32) follows the
+	// payload (before the CRC).
+	//   Tag = authority.IngestTag(key, (port, unit_id), header || payload, nonce)
+	// The nonce is Unix time in nanoseconds (see authority.AuthenticateIngest).
+	FlagAuth = byte(0x01)
+
+	flagsKnown = FlagAuth
+)
@@ -0,0 +1,154 @@
+// internal/transport/rawingest/pipeline.go
+package rawingest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/netip"
+
+	"MMA2.0/internal/authority"
+	"MMA2.0/internal/memorycore"
+)
+
+// Acknowledgement window bounds (frames per cumulative ACK).
+const (
+	DefaultPipelineWindow = 64
+	MaxPipelineWindow     = 4096
+)
+
+// servePipelined runs a connection in pipelined mode.
+//
+// Handshake (already decoded by the caller):
+//
+//	client → Magic(2) 0x04 Rsv(1) Window(2) Rsv(4)
+//	server → Magic(2) 0x04 StatusOK GrantedWindow(4)
+//
+// The producer then streams v2 / batch frames without waiting.
+// Sequence numbers must increase (serial arithmetic, wrap allowed).
+// Every reply uses the 8-byte layout with version 0x04:
+//
+//	ACK:  Status = OK, Seq = last applied frame (cumulative)
+//	NACK: Status != OK, Seq = the failed frame
+//
+// A cumulative ACK covers every frame up to Seq that was not NACKed.
+// Replies are in sequence order: pending successes are acknowledged
+// before a NACK, so an ACK Seq is always a frame that was applied
+// and never a NACKed one. ACKs are sent every
+// window successful frames, and whenever the server has drained all
+// buffered input, so a producer never waits on a partial window.
+//
+// A frame with a good CRC but bad flags is NACKed and the stream
+// continues. Other frame errors (bad magic, version, area, CRC, v1 or a
+// second handshake) lose the frame boundary or are not allowed: they are
+// NACKed and the connection is closed.
+func servePipelined(
+	conn net.Conn,
+	r *bufio.Reader,
+	port uint16,
+	store *memorycore.Store,
+	auth *authority.Authority,
+	srcIP netip.Addr,
+	window uint16,
+) {
+	granted := int(window)
+	switch {
+	case granted == 0:
+		granted = DefaultPipelineWindow
+	case granted > MaxPipelineWindow:
+		granted = MaxPipelineWindow
+	}
+
+	if err := writeReply(conn, VersionPipeline, uint32(granted), StatusOK); err != nil {
+		log.Printf("rawingest write error: %v", err)
+		return
+	}
+
+	var (
+		last    uint32 // last processed seq (ordering)
+		lastOK  uint32 // last applied seq (acknowledged by ack)
+		started bool   // last is valid
+		pending int    // successful frames not yet acknowledged
+	)
+
+	ack := func() error {
+		if pending == 0 {
+			return nil
+		}
+		pending = 0
+		return writeReply(conn, VersionPipeline, lastOK, StatusOK)
+	}
+
+	nack := func(seq uint32, status byte) error {
+		if err := ack(); err != nil {
+			return err
+		}
+		return writeReply(conn, VersionPipeline, seq, status)
+	}
+
+	for {
+		// Nothing left to process without blocking: acknowledge now.
+		if r.Buffered() == 0 {
+			if err := ack(); err != nil {
+				log.Printf("rawingest write error: %v", err)
+				return
+			}
+		}
+
+		pkt, err := DecodeOne(r, port)
+		if err == nil && (pkt.Version == Version1 || pkt.Version == VersionPipeline) {
+			err = &FrameError{Version: pkt.Version, Seq: pkt.Seq, Err: fmt.Errorf("%w: version %d not allowed in pipelined mode", ErrBadFrame, pkt.Version)}
+		}
+		if err != nil {
+			if err == io.EOF {
+				_ = ack()
+				return
+			}
+			var seq uint32
+			var fe *FrameError
+			if errors.As(err, &fe) {
+				seq = fe.Seq
+				if fe.Aligned {
+					if err := nack(seq, StatusFor(err)); err != nil {
+						log.Printf("rawingest write error: %v", err)
+						return
+					}
+					log.Printf("rawingest: rejected frame seq=%d: %v", seq, err)
+					continue
+				}
+			}
+			_ = nack(seq, StatusFor(err))
+			log.Printf("rawingest decode error: %v", err)
+			return
+		}
+
+		if started && int32(pkt.Seq-last) <= 0 {
+			if err := nack(pkt.Seq, StatusBadSeq); err != nil {
+				log.Printf("rawingest write error: %v", err)
+				return
+			}
+			continue
+		}
+		last, started = pkt.Seq, true
+
+		if err := Apply(store, auth, srcIP, pkt); err != nil {
+			if err := nack(pkt.Seq, StatusFor(err)); err != nil {
+				log.Printf("rawingest write error: %v", err)
+				return
+			}
+			continue
+		}
+
+		lastOK = pkt.Seq
+		pending++
+		if pending >= granted {
+			if err := ack(); err != nil {
+				log.Printf("rawingest write error: %v", err)
+				return
+			}
+		}
+	}
+}
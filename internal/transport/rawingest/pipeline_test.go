@@ -0,0 +1,187 @@
+// internal/transport/rawingest/pipeline_test.go
+package rawingest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"MMA2.0/internal/memorycore"
+)
+
+const pipePort = 502
+
+// pipeline runs servePipelined on one end of a net.Pipe and returns the
+// client end and a channel closed when the server returns.
+func pipeline(t *testing.T, window uint16) (net.Conn, *memorycore.Memory, <-chan struct{}) {
+	t.Helper()
+	store, mem, auth := newRegStore(t, pipePort)
+
+	srv, cli := net.Pipe()
+	t.Cleanup(func() { cli.Close() })
+	_ = cli.SetDeadline(time.Now().Add(2 * time.Second))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer srv.Close()
+		r := bufio.NewReader(srv)
+		servePipelined(srv, r, pipePort, store, auth, netip.MustParseAddr("127.0.0.1"), window)
+	}()
+	return cli, mem, done
+}
+
+type pipeReply struct {
+	status byte
+	seq    uint32
+}
+
+func readPipeReply(t *testing.T, r io.Reader) pipeReply {
+	t.Helper()
+	var b [replyLenV2]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		t.Fatal(err)
+	}
+	if b[0] != Magic0 || b[1] != Magic1 || b[2] != VersionPipeline {
+		t.Fatalf("reply % x: not a pipeline reply", b)
+	}
+	return pipeReply{status: b[3], seq: binary.BigEndian.Uint32(b[4:8])}
+}
+
+// expectReplies reads the given replies in order.
+func expectReplies(t *testing.T, r io.Reader, want ...pipeReply) {
+	t.Helper()
+	for i, w := range want {
+		if got := readPipeReply(t, r); got != w {
+			t.Fatalf("reply %d = seq %d status 0x%02x, want seq %d status 0x%02x", i, got.seq, got.status, w.seq, w.status)
+		}
+	}
+}
+
+// frames builds v2 frames writing seq's low byte into register addr.
+func frames(t *testing.T, specs ...[2]uint32) []byte {
+	t.Helper()
+	var out []byte
+	for _, s := range specs {
+		seq, addr := s[0], uint16(s[1])
+		var err error
+		out, err = AppendV2(out, seq, 1, memorycore.AreaHoldingRegs, addr, 1, []byte{0, byte(seq)})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return out
+}
+
+func TestPipelineHandshakeDecode(t *testing.T) {
+	hs := []byte{Magic0, Magic1, VersionPipeline, 0, 0x01, 0x00, 0, 0, 0, 0}
+	pkt, err := DecodeOne(bytes.NewReader(hs), pipePort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkt.Version != VersionPipeline || pkt.Window != 256 {
+		t.Fatalf("packet = %+v, want pipeline window 256", pkt)
+	}
+
+	hs[3] = 1 // reserved byte set
+	if _, err := DecodeOne(bytes.NewReader(hs), pipePort); StatusFor(err) != StatusBadFrame {
+		t.Fatalf("reserved byte: err = %v, want bad frame", err)
+	}
+}
+
+func TestPipelineGrantedWindow(t *testing.T) {
+	for _, tc := range []struct {
+		window  uint16
+		granted uint32
+	}{
+		{0, DefaultPipelineWindow},
+		{3, 3},
+		{MaxPipelineWindow, MaxPipelineWindow},
+		{MaxPipelineWindow + 1, MaxPipelineWindow},
+	} {
+		cli, _, done := pipeline(t, tc.window)
+
+		// The handshake reply echoes the granted window in Seq.
+		expectReplies(t, cli, pipeReply{StatusOK, tc.granted})
+
+		cli.Close()
+		<-done
+	}
+}
+
+func TestPipelineCumulativeAck(t *testing.T) {
+	cli, mem, done := pipeline(t, 3)
+	expectReplies(t, cli, pipeReply{StatusOK, 3})
+
+	// Five frames in one write: a full window is acknowledged at 3, the
+	// rest once the input is drained.
+	if _, err := cli.Write(frames(t, [2]uint32{1, 0}, [2]uint32{2, 1}, [2]uint32{3, 2}, [2]uint32{4, 3}, [2]uint32{5, 0})); err != nil {
+		t.Fatal(err)
+	}
+	expectReplies(t, cli,
+		pipeReply{StatusOK, 3},
+		pipeReply{StatusOK, 5},
+	)
+
+	regs := make([]byte, 8)
+	if err := mem.ReadRegs(memorycore.AreaHoldingRegs, 0, 4, regs); err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0, 5, 0, 2, 0, 3, 0, 4}; string(regs) != string(want) {
+		t.Fatalf("regs = % x, want % x", regs, want)
+	}
+
+	cli.Close()
+	<-done
+}
+
+func TestPipelineAckBeforeNack(t *testing.T) {
+	cli, _, done := pipeline(t, 8)
+	expectReplies(t, cli, pipeReply{StatusOK, 8})
+
+	// 7 targets a register past the area: 6 is acknowledged before the
+	// NACK, and the ACK after it never covers 7.
+	if _, err := cli.Write(frames(t, [2]uint32{6, 0}, [2]uint32{7, 9}, [2]uint32{8, 1})); err != nil {
+		t.Fatal(err)
+	}
+	expectReplies(t, cli,
+		pipeReply{StatusOK, 6},
+		pipeReply{StatusOutOfBounds, 7},
+		pipeReply{StatusOK, 8},
+	)
+
+	// A sequence number that does not increase is NACKed; the stream goes on.
+	if _, err := cli.Write(frames(t, [2]uint32{8, 0}, [2]uint32{9, 0})); err != nil {
+		t.Fatal(err)
+	}
+	expectReplies(t, cli,
+		pipeReply{StatusBadSeq, 8},
+		pipeReply{StatusOK, 9},
+	)
+
+	cli.Close()
+	<-done
+}
+
+func TestPipelineBadCRCCloses(t *testing.T) {
+	cli, _, done := pipeline(t, 8)
+	expectReplies(t, cli, pipeReply{StatusOK, 8})
+
+	bad := frames(t, [2]uint32{2, 1})
+	bad[len(bad)-1] ^= 0xFF
+
+	// Pending ACK 1 is flushed before the fatal NACK.
+	if _, err := cli.Write(append(frames(t, [2]uint32{1, 0}), bad...)); err != nil {
+		t.Fatal(err)
+	}
+	expectReplies(t, cli,
+		pipeReply{StatusOK, 1},
+		pipeReply{StatusBadCRC, 2},
+	)
+	<-done
+}
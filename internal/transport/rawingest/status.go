@@ -0,0 +1,92 @@
+// internal/transport/rawingest/status.go
+package rawingest
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"MMA2.0/internal/authority"
+	"MMA2.0/internal/memorycore"
+)
+
+// v2 status codes (reply byte 3).
+// StatusOK / StatusRejected keep the v1 meaning of RespOK / RespRejected.
+const (
+	StatusOK               = byte(0x00)
+	StatusRejected         = byte(0x01) // unclassified rejection
+	StatusBadFrame         = byte(0x02) // magic / version / area / count / flags
+	StatusBadCRC           = byte(0x03)
+	StatusUnknownMemory    = byte(0x04) // memorycore.ErrUnknownMemoryID
+	StatusAreaNotDefined   = byte(0x05) // memorycore.ErrAreaNotDefined
+	StatusOutOfBounds      = byte(0x06) // memorycore.ErrOutOfBounds
+	StatusInvalidArea      = byte(0x07) // memorycore.ErrInvalidArea
+	StatusNotAuthorized    = byte(0x08) // ingest policy denied
+	StatusNotAuthenticated = byte(0x09) // missing / invalid HMAC tag
+	StatusReplay           = byte(0x0A) // nonce not greater than last accepted
+	StatusBadSeq           = byte(0x0B) // pipelined: seq not after the previous frame
+)
+
+// replyLenV2: Magic(2) Ver(1) Status(1) Seq(4)
+const replyLenV2 = 8
+
+// StatusFor maps a decode / authority / memorycore error to a status code.
+func StatusFor(err error) byte {
+	switch {
+	case err == nil:
+		return StatusOK
+	case errors.Is(err, ErrBadCRC):
+		return StatusBadCRC
+	case errors.Is(err, ErrBadSeq):
+		return StatusBadSeq
+	case errors.Is(err, ErrBadMagic),
+		errors.Is(err, ErrBadVersion),
+		errors.Is(err, ErrBadFlags),
+		errors.Is(err, ErrBadFrame),
+		errors.Is(err, ErrBadArea),
+		errors.Is(err, ErrCountZero):
+		return StatusBadFrame
+	case errors.Is(err, ErrNotAuthorized):
+		return StatusNotAuthorized
+	case errors.Is(err, authority.ErrIngestReplay):
+		return StatusReplay
+	case errors.Is(err, ErrNotAuthenticated),
+		errors.Is(err, authority.ErrIngestAuthFailed),
+		errors.Is(err, authority.ErrIngestAuthNotConfigured):
+		return StatusNotAuthenticated
+	case errors.Is(err, memorycore.ErrUnknownMemoryID):
+		return StatusUnknownMemory
+	case errors.Is(err, memorycore.ErrAreaNotDefined):
+		return StatusAreaNotDefined
+	case errors.Is(err, memorycore.ErrOutOfBounds):
+		return StatusOutOfBounds
+	case errors.Is(err, memorycore.ErrInvalidArea):
+		return StatusInvalidArea
+	default:
+		return StatusRejected
+	}
+}
+
+// writeReply writes the reply for one packet in its wire version:
+//
+//	v1: 1 byte (RespOK / RespRejected)
+//	v2, batch, pipeline: Magic(2) Ver(1) Status(1) Seq(4)
+func writeReply(w io.Writer, version byte, seq uint32, status byte) error {
+	if version == Version1 {
+		b := RespOK
+		if status != StatusOK {
+			b = RespRejected
+		}
+		_, err := w.Write([]byte{b})
+		return err
+	}
+
+	var out [replyLenV2]byte
+	out[0] = Magic0
+	out[1] = Magic1
+	out[2] = version
+	out[3] = status
+	binary.BigEndian.PutUint32(out[4:8], seq)
+	_, err := w.Write(out[:])
+	return err
+}
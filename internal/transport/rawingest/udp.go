@@ -0,0 +1,176 @@
+// internal/transport/rawingest/udp.go
+package rawingest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/netip"
+	"sync"
+	"time"
+
+	"MMA2.0/internal/authority"
+	"MMA2.0/internal/memorycore"
+)
+
+// maxUDPSources caps the per-source counter table.
+// Sources beyond the cap are counted under the zero address.
+const maxUDPSources = 4096
+
+// udpStatsInterval: per-source counters are logged at this interval
+// while they change (see LogStats).
+const udpStatsInterval = time.Minute
+
+// UDPStats are the counters of one datagram source.
+type UDPStats struct {
+	Received uint64
+	Accepted uint64
+	Rejected uint64
+}
+
+func (s UDPStats) String() string {
+	return fmt.Sprintf("received=%d accepted=%d rejected=%d", s.Received, s.Accepted, s.Rejected)
+}
+
+// UDPHandler applies raw ingest datagrams to memory.
+//
+// One datagram carries exactly one v1, v2 or batch packet.
+// Truncated datagrams and trailing bytes are rejected.
+// Rejections are not logged per datagram (any host, or spoofed source,
+// reaching the port could flood the log): LogStats reports them.
+// As with TCP, the target port is the port the datagram arrived on.
+type UDPHandler struct {
+	store *memorycore.Store
+	auth  *authority.Authority
+	port  uint16
+	reply bool
+
+	mu    sync.Mutex
+	stats map[netip.Addr]*UDPStats
+}
+
+// NewUDPHandler creates a datagram handler for the given local port.
+// When reply is true, HandleDatagram returns a status datagram
+// in the packet's wire version (see HandleConn).
+func NewUDPHandler(store *memorycore.Store, auth *authority.Authority, port uint16, reply bool) *UDPHandler {
+	return &UDPHandler{
+		store: store,
+		auth:  auth,
+		port:  port,
+		reply: reply,
+		stats: make(map[netip.Addr]*UDPStats),
+	}
+}
+
+// HandleDatagram decodes and applies one datagram from src.
+// It returns the reply datagram, or nil when no reply is sent.
+func (h *UDPHandler) HandleDatagram(data []byte, src netip.Addr) []byte {
+	r := bytes.NewReader(data)
+
+	pkt, err := DecodeOne(r, h.port)
+	if err == nil && pkt.Version == VersionPipeline {
+		err = &FrameError{Version: VersionPipeline, Err: fmt.Errorf("%w: pipelining is TCP only", ErrBadFrame)}
+	}
+	if err == nil && r.Len() != 0 {
+		err = &FrameError{Version: pkt.Version, Seq: pkt.Seq, Err: fmt.Errorf("%w: %d trailing bytes", ErrBadFrame, r.Len())}
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		err = fmt.Errorf("%w: truncated datagram", ErrBadFrame)
+	}
+
+	if err != nil {
+		h.count(src, false)
+
+		if !h.reply {
+			return nil
+		}
+
+		var out bytes.Buffer
+		var fe *FrameError
+		if errors.As(err, &fe) {
+			_ = writeReply(&out, fe.Version, fe.Seq, StatusFor(err))
+		} else {
+			out.WriteByte(RespRejected)
+		}
+		return out.Bytes()
+	}
+
+	err = apply(h.store, h.auth, src, pkt)
+	h.count(src, err == nil)
+
+	if !h.reply {
+		return nil
+	}
+
+	var out bytes.Buffer
+	_ = writeReply(&out, pkt.Version, pkt.Seq, StatusFor(err))
+	return out.Bytes()
+}
+
+// Stats returns a snapshot of the per-source counters.
+func (h *UDPHandler) Stats() map[netip.Addr]UDPStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	out := make(map[netip.Addr]UDPStats, len(h.stats))
+	for src, s := range h.stats {
+		out[src] = *s
+	}
+	return out
+}
+
+// LogStats logs the counters of every source that changed since the
+// previous report, once per udpStatsInterval, until done is closed.
+// id names the listener in log lines.
+func (h *UDPHandler) LogStats(id string, done <-chan struct{}) {
+	t := time.NewTicker(udpStatsInterval)
+	defer t.Stop()
+
+	last := make(map[netip.Addr]UDPStats)
+	for {
+		select {
+		case <-done:
+			return
+		case <-t.C:
+			for src, st := range h.Stats() {
+				if st == last[src] {
+					continue
+				}
+				last[src] = st
+
+				name := src.String()
+				if !src.IsValid() {
+					name = "(other sources)"
+				}
+				log.Printf("ingress %s: udp source %s: %s", id, name, st)
+			}
+		}
+	}
+}
+
+// count records one datagram for src.
+func (h *UDPHandler) count(src netip.Addr, accepted bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.stats[src]
+	if !ok {
+		if len(h.stats) >= maxUDPSources {
+			src = netip.Addr{}
+			s = h.stats[src]
+		}
+		if s == nil {
+			s = &UDPStats{}
+			h.stats[src] = s
+		}
+	}
+
+	s.Received++
+	if accepted {
+		s.Accepted++
+	} else {
+		s.Rejected++
+	}
+}
@@ -0,0 +1,57 @@
+// internal/transport/rest/errors.go
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"MMA2.0/internal/ingest"
+)
+
+// Transport-level error codes (write errors use the ingest codes).
+const (
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeTooLarge         = "payload_too_large"
+)
+
+// errorBody is the JSON shape of every failure response:
+//
+//	{"error":{"code":"out_of_bounds","message":"..."}}
+type errorBody struct {
+	Error errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// classify maps a write error to an HTTP status and error code.
+func classify(err error) (int, string) {
+	code := ingest.CodeFor(err)
+
+	switch code {
+	case ingest.CodeBadRequest, ingest.CodeInvalidArea:
+		return http.StatusBadRequest, code
+	case ingest.CodeUnknownMemory:
+		return http.StatusNotFound, code
+	case ingest.CodeNotAuthorized:
+		return http.StatusForbidden, code
+	case ingest.CodeNotAuthenticated, ingest.CodeReplay:
+		return http.StatusUnauthorized, code
+	default:
+		return http.StatusUnprocessableEntity, code
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, code, msg string) {
+	writeJSON(w, status, errorBody{Error: errorDetail{Code: code, Message: msg}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
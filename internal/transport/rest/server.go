@@ -0,0 +1,183 @@
+// internal/transport/rest/server.go
+package rest
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"MMA2.0/internal/authority"
+	"MMA2.0/internal/ingest"
+	"MMA2.0/internal/memorycore"
+)
+
+// REST is a write-only ingest transport (docs/06_TRANSPORTS.md).
+// It exposes exactly one endpoint, POST <path>, and never serves reads.
+//
+// Authenticated requests (memories with ingest_auth) carry:
+//
+//	X-Ingest-Nonce: <decimal Unix time in ns, strictly increasing per memory>
+//	X-Ingest-Tag:   <hex authority.IngestTag(key, (port, unit_id), body, nonce)>
+const (
+	HeaderNonce = "X-Ingest-Nonce"
+	HeaderTag   = "X-Ingest-Tag"
+)
+
+// Defaults.
+const (
+	DefaultPath         = "/write"
+	DefaultMaxBodyBytes = 1 << 20
+)
+
+// Options configures one REST ingest server.
+type Options struct {
+	ID     string
+	Listen string
+
+	// Path of the write endpoint (DefaultPath when empty).
+	Path string
+
+	// MaxBodyBytes caps the request body (DefaultMaxBodyBytes when <= 0).
+	MaxBodyBytes int64
+
+	// Permit is the ingress firewall (nil = accept all).
+	Permit func(netip.Addr) (bool, string)
+}
+
+// Server is a REST ingest server.
+type Server struct {
+	opts  Options
+	store *memorycore.Store
+	auth  *authority.Authority
+}
+
+// NewServer creates a REST ingest server.
+func NewServer(opts Options, store *memorycore.Store, auth *authority.Authority) *Server {
+	if opts.Path == "" {
+		opts.Path = DefaultPath
+	}
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	return &Server{opts: opts, store: store, auth: auth}
+}
+
+// ListenAndServe starts the HTTP listener.
+func (s *Server) ListenAndServe() error {
+	srv := &http.Server{
+		Addr:              s.opts.Listen,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+	}
+
+	log.Printf("rest %s listening on %s (POST %s)", s.opts.ID, s.opts.Listen, s.opts.Path)
+	return srv.ListenAndServe()
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	src := remoteIP(r.RemoteAddr)
+
+	if s.opts.Permit != nil {
+		if ok, reason := s.opts.Permit(src); !ok {
+			log.Printf("rest %s: firewall rejected %s (%s)", s.opts.ID, r.RemoteAddr, reason)
+			writeError(w, http.StatusForbidden, CodeForbidden, "source not permitted")
+			return
+		}
+	}
+
+	if r.URL.Path != s.opts.Path {
+		writeError(w, http.StatusNotFound, CodeNotFound, "unknown endpoint")
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "write-only endpoint: POST only")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.opts.MaxBodyBytes))
+	if err != nil {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			writeError(w, http.StatusRequestEntityTooLarge, CodeTooLarge, fmt.Sprintf("body exceeds %d bytes", s.opts.MaxBodyBytes))
+			return
+		}
+		writeError(w, http.StatusBadRequest, ingest.CodeBadRequest, "failed to read body")
+		return
+	}
+
+	n, err := s.write(src, r.Header, body)
+	if err != nil {
+		status, code := classify(err)
+		log.Printf("rest %s: rejected %s: %v", s.opts.ID, src, err)
+		writeError(w, status, code, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, struct {
+		Status string `json:"status"`
+		Writes int    `json:"writes"`
+	}{Status: "ok", Writes: n})
+}
+
+// write decodes, authenticates and applies one request body.
+// It returns the number of writes applied.
+func (s *Server) write(src netip.Addr, h http.Header, body []byte) (int, error) {
+	req, err := ingest.DecodeWriteRequest(body)
+	if err != nil {
+		return 0, err
+	}
+
+	mid, writes, err := req.Resolve()
+	if err != nil {
+		return 0, err
+	}
+
+	isrc := ingest.Source{IP: src}
+
+	if h.Get(HeaderNonce) != "" || h.Get(HeaderTag) != "" {
+		nonce, err := strconv.ParseUint(strings.TrimSpace(h.Get(HeaderNonce)), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: invalid %s", ingest.ErrBadRequest, HeaderNonce)
+		}
+		tag, err := hex.DecodeString(strings.TrimSpace(h.Get(HeaderTag)))
+		if err != nil {
+			return 0, fmt.Errorf("%w: invalid %s", ingest.ErrBadRequest, HeaderTag)
+		}
+		if err := s.auth.AuthenticateIngest(mid, body, nonce, tag); err != nil {
+			return 0, err
+		}
+		isrc.Authenticated = true
+	}
+
+	if err := ingest.Apply(s.store, s.auth, isrc, mid, writes); err != nil {
+		return 0, err
+	}
+
+	return len(writes), nil
+}
+
+// remoteIP extracts the source IP of an HTTP request.
+func remoteIP(remoteAddr string) netip.Addr {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return netip.Addr{}
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return ip.Unmap()
+}